@@ -1,57 +1,195 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/amiosamu/interview-match-bot/internal/bot"
-	"github.com/joho/godotenv"
+	"github.com/amiosamu/interview-match-bot/internal/config"
+	"github.com/amiosamu/interview-match-bot/internal/logging"
+	"github.com/amiosamu/interview-match-bot/internal/monitoring"
+	"github.com/amiosamu/interview-match-bot/internal/quizbank"
+	"github.com/amiosamu/interview-match-bot/internal/quizlint"
+	"github.com/amiosamu/interview-match-bot/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
 
 func main() {
-	// Load .env file if it exists
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found or error loading it. Will use environment variables.")
+	configFile := flag.String("config", "", "YAML configuration file (default $CONFIG_FILE)")
+	flag.Parse()
+
+	// Stop on Ctrl+C and when the container is stopped
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Settings come from the config file, .env and environment variables
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
 	}
 
-	// Get the bot token from environment variable
-	token := os.Getenv("TELEGRAM_BOT_TOKEN")
-	if token == "" {
-		log.Fatalf("TELEGRAM_BOT_TOKEN environment variable not set")
+	// Log JSON lines at the configured level, also for the log package and the Telegram client
+	logger := logging.New(os.Stdout, cfg.LogLevel)
+	slog.SetDefault(logger)
+	tgbotapi.SetLogger(logging.Printer{Logger: logger, Level: slog.LevelDebug})
+
+	slog.Info("Loaded configuration", "config", cfg.Redacted())
+
+	// Connect to the database.
+	// Without one the bot runs in development mode and keeps quizzes in memory.
+	var db *sql.DB
+	var quizRepository service.QuizRepository
+	if cfg.DatabaseURL != "" {
+		db, err = sql.Open(monitoring.PostgresDriver, cfg.DatabaseURL)
+		if err != nil {
+			fatal("Error connecting to database", "error", err)
+		}
+		defer db.Close()
+
+		// Verify the connection
+		if err := db.Ping(); err != nil {
+			fatal("Error pinging database", "error", err)
+		}
+
+		slog.Info("Connected to database")
+		quizRepository = service.NewPostgresQuizRepository(db)
+	} else {
+		slog.Warn("DATABASE_URL not set. Running in development mode: quizzes are kept in memory, duels, daily challenges and bookmarks are unavailable.")
+		quizRepository = service.NewMemoryQuizRepository()
+
+		if file := cfg.Quiz.QuestionsFile; file != "" {
+			count, err := loadQuestions(ctx, quizRepository, file)
+			if err != nil {
+				fatal("Error loading questions", "file", file, "error", err)
+			}
+			slog.Info("Loaded quiz questions", "count", count, "file", file)
+		}
 	}
 
-	// Get database connection string
-	dbURL := os.Getenv("DATABASE_URL")
-	if dbURL == "" {
-		log.Println("DATABASE_URL not set. Quiz functionality will not work properly.")
+	// Check the question bank for questions that can't be answered or rendered
+	report, err := quizlint.Run(ctx, service.NewQuizService(quizRepository), "", cfg.Quiz.DisableInvalidQuestions)
+	if err != nil {
+		slog.Error("Error validating quiz questions", "error", err)
+	} else {
+		for _, issue := range report.Issues {
+			slog.Warn("Invalid quiz question", "issue", issue.String())
+		}
+		slog.Info("Validated quiz questions", "checked", report.Checked, "issues", len(report.Issues), "disabled", len(report.Disabled))
 	}
 
-	// Connect to the database
-	db, err := sql.Open("postgres", dbURL)
+	// Create a new bot instance
+	interviewBot, err := bot.NewBot(cfg, db, quizRepository)
 	if err != nil {
-		log.Fatalf("Error connecting to database: %v", err)
+		fatal("Error creating bot", "error", err)
+	}
+
+	// Log every request to Telegram at the debug level; personal fields are redacted
+	interviewBot.Debug(cfg.LogLevel == "debug")
+
+	// Serve the health, readiness and metrics endpoints
+	var monitoringServer *http.Server
+	if cfg.Monitoring.ListenAddr != "" {
+		checks := []monitoring.Check{{Name: "telegram", Run: interviewBot.CheckTelegram}}
+		if db != nil {
+			checks = append(checks, monitoring.Check{Name: "database", Run: db.PingContext})
+		}
+		monitoringServer = monitoring.NewServer(cfg.Monitoring.ListenAddr, checks...)
+
+		go func() {
+			slog.Info("Serving health checks and metrics", "listen_addr", cfg.Monitoring.ListenAddr)
+			if err := monitoringServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Error serving health checks and metrics", "error", err)
+			}
+		}()
 	}
-	defer db.Close()
 
-	// Verify the connection
-	if err := db.Ping(); err != nil {
-		log.Fatalf("Error pinging database: %v", err)
+	// Start the bot; it returns once a stop signal arrives.
+	// Updates are received through a webhook or by long polling, depending on the transport.
+	slog.Info("Starting Interview Match Bot", "transport", cfg.Transport)
+	if cfg.Transport == config.TransportWebhook {
+		err := interviewBot.StartWebhook(ctx, bot.WebhookConfig{
+			URL:         cfg.Webhook.URL,
+			ListenAddr:  cfg.Webhook.ListenAddr,
+			SecretToken: cfg.Webhook.SecretToken,
+			CertFile:    cfg.Webhook.CertFile,
+			KeyFile:     cfg.Webhook.KeyFile,
+		})
+		if err != nil {
+			slog.Error("Error receiving updates", "error", err)
+		}
+	} else {
+		interviewBot.Start(ctx)
 	}
 
-	log.Println("Connected to database successfully")
+	// A second signal during shutdown stops the bot right away
+	stop()
 
-	// Create a new bot instance
-	interviewBot, err := bot.NewBot(token, db)
+	slog.Info("Shutting down, finishing the updates received so far")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Updates.ShutdownTimeout)
+	defer cancel()
+
+	err = interviewBot.Shutdown(shutdownCtx)
+
+	// Metrics stay available until the last updates are handled
+	if monitoringServer != nil {
+		monitoringServer.Close()
+	}
+
+	if err != nil {
+		slog.Error("Error shutting down", "error", err)
+		return
+	}
+
+	slog.Info("Interview Match Bot stopped")
+}
+
+// loadQuestions adds the questions of a question bank file to repo and returns how many it added.
+// Invalid questions are skipped; quizctl import reports them in detail.
+func loadQuestions(ctx context.Context, repo service.QuizRepository, file string) (int, error) {
+	format, err := quizbank.FormatFromPath(file)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	bank, err := quizbank.Read(f, format)
 	if err != nil {
-		log.Fatalf("Error creating bot: %v", err)
+		return 0, err
+	}
+
+	count := 0
+	for i := range bank {
+		question, err := bank[i].ToModel()
+		if err != nil {
+			slog.Warn("Skipping invalid question", "number", i+1, "question", fmt.Sprintf("%.40q", bank[i].Question), "error", err)
+			continue
+		}
+		if _, err := repo.UpsertQuestion(ctx, question); err != nil {
+			return count, err
+		}
+		count++
 	}
 
-	// Enable debug mode for development
-	interviewBot.Debug(true)
+	return count, nil
+}
 
-	// Start the bot
-	log.Println("Starting Interview Match Bot...")
-	interviewBot.Start()
+// fatal logs an error and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
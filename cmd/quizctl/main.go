@@ -0,0 +1,254 @@
+// Command quizctl imports and exports quiz question banks.
+//
+// Usage:
+//
+//	quizctl import -file questions.yaml [-format yaml] [-dry-run] [-skip-invalid]
+//	quizctl export -file questions.csv [-format csv] [-language golang]
+//	quizctl lint [-language golang] [-disable]
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/amiosamu/interview-match-bot/internal/quizbank"
+	"github.com/amiosamu/interview-match-bot/internal/quizlint"
+	"github.com/amiosamu/interview-match-bot/internal/service"
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq" // PostgreSQL driver
+)
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	// Load .env file if it exists
+	godotenv.Load()
+
+	switch os.Args[1] {
+	case "import":
+		runImport(os.Args[2:])
+	case "export":
+		runExport(os.Args[2:])
+	case "lint":
+		runLint(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+// usage prints the available commands and exits
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage: quizctl <command> [flags]
+
+Commands:
+  import   validate a question bank file and upsert it into quiz_questions
+  export   write quiz_questions to a question bank file
+  lint     check the questions in quiz_questions and optionally disable broken ones
+
+Formats: yaml, json, csv (guessed from the file extension by default).
+Run "quizctl <command> -h" for the flags of a command.`)
+	os.Exit(2)
+}
+
+// runImport validates a question bank and upserts it into the database
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "question bank file to import (required)")
+	formatName := flags.String("format", "", "file format: yaml, json or csv")
+	dryRun := flags.Bool("dry-run", false, "validate the file without writing to the database")
+	skipInvalid := flags.Bool("skip-invalid", false, "import valid questions even if some are invalid")
+	flags.Parse(args)
+
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+
+	format := resolveFormat(*file, *formatName)
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Error opening %s: %v", *file, err)
+	}
+	defer f.Close()
+
+	bank, err := quizbank.Read(f, format)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", *file, err)
+	}
+
+	// Validate every question and drop duplicates within the file
+	var questions []*models.QuizQuestion
+	seen := make(map[string]int)
+	invalid, duplicates := 0, 0
+	for i := range bank {
+		question, err := bank[i].ToModel()
+		if err != nil {
+			log.Printf("Question %d (%.40q) is invalid:\n  - %s", i+1, bank[i].Question, strings.ReplaceAll(err.Error(), "\n", "\n  - "))
+			invalid++
+			continue
+		}
+
+		// Catch questions that are complete but would fail to render
+		issues := quizlint.Lint(question)
+		for _, issue := range issues {
+			log.Printf("Question %d (%.40q): %s: %s", i+1, bank[i].Question, issue.Severity, issue.Message)
+		}
+		if quizlint.HasErrors(issues) {
+			invalid++
+			continue
+		}
+
+		hash := question.TextHash()
+		if first, exists := seen[hash]; exists {
+			log.Printf("Question %d duplicates question %d, skipping", i+1, first)
+			duplicates++
+			continue
+		}
+		seen[hash] = i + 1
+
+		questions = append(questions, question)
+	}
+
+	if invalid > 0 && !*skipInvalid {
+		log.Fatalf("%d of %d questions are invalid, nothing was imported. Fix them or use -skip-invalid.", invalid, len(bank))
+	}
+
+	if *dryRun {
+		log.Printf("%d questions are valid, %d invalid, %d duplicates. Dry run, nothing was imported.", len(questions), invalid, duplicates)
+		return
+	}
+
+	quizService := service.NewQuizService(service.NewPostgresQuizRepository(openDB()))
+
+	inserted, updated := 0, 0
+	for _, question := range questions {
+		isNew, err := quizService.UpsertQuestion(context.Background(), question)
+		if err != nil {
+			log.Fatalf("Error importing question %.40q: %v", question.QuestionText, err)
+		}
+
+		if isNew {
+			inserted++
+		} else {
+			updated++
+		}
+	}
+
+	log.Printf("Imported %s: %d inserted, %d updated, %d invalid, %d duplicates", *file, inserted, updated, invalid, duplicates)
+}
+
+// runExport writes the questions in the database to a question bank
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	file := flags.String("file", "-", "file to write, - for standard output")
+	formatName := flags.String("format", "", "file format: yaml, json or csv")
+	language := flags.String("language", "", "only export questions for this language")
+	flags.Parse(args)
+
+	if *file == "-" && *formatName == "" {
+		*formatName = string(quizbank.FormatYAML)
+	}
+	format := resolveFormat(*file, *formatName)
+
+	questions, err := service.NewQuizService(service.NewPostgresQuizRepository(openDB())).ListQuestions(context.Background(), *language)
+	if err != nil {
+		log.Fatalf("Error loading questions: %v", err)
+	}
+
+	bank := make([]quizbank.Question, 0, len(questions))
+	for _, question := range questions {
+		bank = append(bank, quizbank.FromModel(question))
+	}
+
+	var w io.Writer = os.Stdout
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			log.Fatalf("Error creating %s: %v", *file, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := quizbank.Write(w, format, bank); err != nil {
+		log.Fatalf("Error writing questions: %v", err)
+	}
+
+	if *file != "-" {
+		log.Printf("Exported %d questions to %s", len(bank), *file)
+	}
+}
+
+// runLint reports questions in the database that can't be answered or rendered
+func runLint(args []string) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	language := flags.String("language", "", "only check questions for this language")
+	disable := flags.Bool("disable", false, "disable questions with errors so they are no longer used in quizzes")
+	flags.Parse(args)
+
+	report, err := quizlint.Run(context.Background(), service.NewQuizService(service.NewPostgresQuizRepository(openDB())), *language, *disable)
+	if err != nil {
+		log.Fatalf("Error checking questions: %v", err)
+	}
+
+	errs := 0
+	for _, issue := range report.Issues {
+		log.Println(issue)
+		if issue.Severity == quizlint.SeverityError {
+			errs++
+		}
+	}
+
+	log.Printf("Checked %d questions: %d issues, %d errors, %d questions disabled",
+		report.Checked, len(report.Issues), errs, len(report.Disabled))
+
+	if errs > 0 && !*disable {
+		os.Exit(1)
+	}
+}
+
+// resolveFormat uses the -format flag if given, or the file extension otherwise
+func resolveFormat(file, name string) quizbank.Format {
+	var format quizbank.Format
+	var err error
+	if name != "" {
+		format, err = quizbank.ParseFormat(name)
+	} else {
+		format, err = quizbank.FormatFromPath(file)
+	}
+
+	if err != nil {
+		log.Fatalf("%v. Use -format to choose yaml, json or csv.", err)
+	}
+	return format
+}
+
+// openDB connects to the database given by DATABASE_URL
+func openDB() *sql.DB {
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable not set")
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		log.Fatalf("Error pinging database: %v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,26 @@
+-- Golang multi-select, free-text and output questions
+INSERT INTO quiz_questions (language, category, difficulty, type, question_text, answer_options, correct_answer, correct_option_indexes, accepted_answers, explanation)
+VALUES
+('golang', 'types', 'intermediate', 'multi',
+ 'Which of the following types are reference-like types in Go?',
+ '["map", "slice", "array", "struct", "channel"]',
+ '',
+ '[0, 1, 4]',
+ NULL,
+ 'Maps, slices and channels contain pointers to underlying data structures, so copies share that data. Arrays and structs are copied by value.'),
+
+('golang', 'concurrency', 'beginner', 'text',
+ 'Which keyword starts a new goroutine?',
+ '[]',
+ 'go',
+ NULL,
+ '["go keyword", "the go keyword"]',
+ 'Prefixing a function call with the go keyword runs it in a new goroutine.'),
+
+('golang', 'functions', 'intermediate', 'output',
+ E'What does this code print?\n\nfunc main() {\n  for i := 0; i < 3; i++ {\n    defer fmt.Println(i)\n  }\n}',
+ '[]',
+ E'2\n1\n0',
+ NULL,
+ NULL,
+ 'Deferred calls are executed in last-in-first-out order when the surrounding function returns, and their arguments are evaluated when the defer statement runs.');
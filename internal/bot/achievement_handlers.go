@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// unlockAchievements evaluates the achievements an event can unlock for a user
+// and announces the new ones in the chat where the event happened
+func (b *Bot) unlockAchievements(ctx context.Context, chatID int64, userID int64, event string) {
+	if b.db == nil {
+		return
+	}
+
+	unlocked, err := b.achievementService.Evaluate(ctx, userID, event, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "Error evaluating achievements", "user_id", userID, "error", err)
+		// Continue anyway - achievements unlocked before the error are still announced
+	}
+
+	for _, achievement := range unlocked {
+		var text string
+		if chatID == userID {
+			text = fmt.Sprintf("🏅 *Achievement unlocked!*\n\n%s *%s* - %s\n\nType /achievements to see all your achievements.",
+				achievement.Emoji, achievement.Title, achievement.Description)
+		} else {
+			text = fmt.Sprintf("🏅 %s unlocked the achievement %s *%s*!",
+				escapeMarkdown(b.memberName(chatID, userID)), achievement.Emoji, achievement.Title)
+		}
+
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = "Markdown"
+		b.send(msg)
+	}
+}
+
+// handleAchievementsCommand lists the user's unlocked achievements and the ones still to earn
+func (b *Bot) handleAchievementsCommand(ctx context.Context, message *tgbotapi.Message) {
+	user := b.saveUserInfo(ctx, message.From)
+
+	unlocked, err := b.achievementService.GetUnlocked(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving achievements", "error", err)
+		b.sendMessage(message.Chat.ID, "Sorry, I couldn't load your achievements. Please try again later.", nil)
+		return
+	}
+
+	unlockedAt := make(map[string]time.Time)
+	for _, achievement := range unlocked {
+		unlockedAt[achievement.ID] = achievement.UnlockedAt
+	}
+
+	text := fmt.Sprintf("🏅 *Your Achievements* (%d of %d)\n\n", len(unlocked), len(models.Achievements))
+	for _, achievement := range models.Achievements {
+		if at, ok := unlockedAt[achievement.ID]; ok {
+			text += fmt.Sprintf("%s *%s* - %s\n_Unlocked on %s_\n\n", achievement.Emoji, achievement.Title, achievement.Description, at.Format("January 2, 2006"))
+		} else {
+			text += fmt.Sprintf("🔒 *%s* - %s\n\n", achievement.Title, achievement.Description)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "Markdown"
+	b.send(msg)
+}
@@ -0,0 +1,237 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Bookmark list settings
+const (
+	bookmarksPageSize     = 5  // bookmarks listed per page
+	bookmarkPreviewLength = 60 // characters of a question shown in the list
+)
+
+// bookmarkButton saves a question to the bookmarks of the user who presses it
+func bookmarkButton(questionID int) tgbotapi.InlineKeyboardButton {
+	return tgbotapi.NewInlineKeyboardButtonData("🔖 Bookmark", fmt.Sprintf("bookmark:add:%d", questionID))
+}
+
+// bookmarkKeyboard is the keyboard of an explanation message or quiz poll, letting users bookmark its question
+func bookmarkKeyboard(questionID int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(bookmarkButton(questionID)))
+}
+
+// handleBookmarksCommand lists the first page of the user's bookmarks
+func (b *Bot) handleBookmarksCommand(ctx context.Context, message *tgbotapi.Message) {
+	if !message.Chat.IsPrivate() {
+		b.sendMessage(message.Chat.ID, "🔖 Bookmarks are personal. Message me directly and type /bookmarks to see yours.", nil)
+		return
+	}
+
+	user := b.saveUserInfo(ctx, message.From)
+
+	text, keyboard, err := b.renderBookmarks(ctx, user.ID, 0)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving bookmarks", "error", err)
+		b.sendMessage(message.Chat.ID, "Sorry, I couldn't load your bookmarks. Please try again later.", nil)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "Markdown"
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+	b.send(msg)
+}
+
+// handleBookmarkCallback processes bookmark button actions.
+// The callback query is answered here, so saving a bookmark is confirmed without a new message.
+func (b *Bot) handleBookmarkCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	user := b.saveUserInfo(ctx, query.From)
+	chatID := query.Message.Chat.ID
+	parts := strings.Split(query.Data, ":")
+
+	var answer string
+	switch {
+	case strings.HasPrefix(query.Data, "bookmark:add:") && len(parts) == 3:
+		answer = b.addBookmark(ctx, user.ID, parts[2])
+
+	case strings.HasPrefix(query.Data, "bookmark:page:") && len(parts) == 3:
+		page, err := strconv.Atoi(parts[2])
+		if err != nil {
+			answer = "Invalid page."
+			break
+		}
+		b.showBookmarksPage(ctx, query.Message, user.ID, page)
+
+	case strings.HasPrefix(query.Data, "bookmark:view:") && len(parts) == 3:
+		questionID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			answer = "Invalid question."
+			break
+		}
+		b.sendBookmark(ctx, chatID, questionID)
+
+	case strings.HasPrefix(query.Data, "bookmark:remove:") && len(parts) == 3:
+		answer = b.removeBookmark(ctx, query.Message, user.ID, parts[2])
+
+	case query.Data == "bookmark:quiz":
+		// Bookmark quizzes offer the same modes as any other quiz
+		b.sendQuizModeSelection(chatID, models.BookmarksLanguage)
+
+	default:
+		answer = "Invalid option. Please try again."
+	}
+
+	b.request(tgbotapi.NewCallback(query.ID, answer))
+}
+
+// addBookmark saves a question for a user and returns the confirmation to show
+func (b *Bot) addBookmark(ctx context.Context, userID int64, questionIDValue string) string {
+	questionID, err := strconv.Atoi(questionIDValue)
+	if err != nil {
+		return "Invalid question."
+	}
+
+	added, err := b.bookmarkService.AddBookmark(ctx, userID, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error adding bookmark", "error", err)
+		return "Sorry, I couldn't save the bookmark. Please try again later."
+	}
+
+	if !added {
+		return "🔖 This question is already in your /bookmarks."
+	}
+	return "🔖 Bookmarked! Find it with /bookmarks."
+}
+
+// removeBookmark removes a question from a user's bookmarks, replacing the bookmark message,
+// and returns the confirmation to show
+func (b *Bot) removeBookmark(ctx context.Context, message *tgbotapi.Message, userID int64, questionIDValue string) string {
+	questionID, err := strconv.Atoi(questionIDValue)
+	if err != nil {
+		return "Invalid question."
+	}
+
+	if _, err := b.bookmarkService.RemoveBookmark(ctx, userID, questionID); err != nil {
+		slog.ErrorContext(ctx, "Error removing bookmark", "error", err)
+		return "Sorry, I couldn't remove the bookmark. Please try again later."
+	}
+
+	b.send(tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, "🗑 Removed from your bookmarks."))
+	return "Removed from your bookmarks."
+}
+
+// showBookmarksPage replaces a bookmark list message with another page
+func (b *Bot) showBookmarksPage(ctx context.Context, message *tgbotapi.Message, userID int64, page int) {
+	text, keyboard, err := b.renderBookmarks(ctx, userID, page)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving bookmarks", "error", err)
+		b.sendMessage(message.Chat.ID, "Sorry, I couldn't load your bookmarks. Please try again later.", nil)
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, text, keyboard)
+	edit.ParseMode = "Markdown"
+	b.send(edit)
+}
+
+// renderBookmarks builds the text and keyboard of a page of a user's bookmarks.
+// Pages past the end show the last page.
+func (b *Bot) renderBookmarks(ctx context.Context, userID int64, page int) (string, tgbotapi.InlineKeyboardMarkup, error) {
+	count, err := b.bookmarkService.CountBookmarks(ctx, userID)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	if count == 0 {
+		text := "🔖 *Your bookmarks*\n\nYou haven't bookmarked any questions yet. " +
+			"Press *🔖 Bookmark* on a quiz question or explanation to save it here."
+		return text, tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}, nil
+	}
+
+	pages := (count + bookmarksPageSize - 1) / bookmarksPageSize
+	if page >= pages {
+		page = pages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	questions, err := b.bookmarkService.GetBookmarks(ctx, userID, bookmarksPageSize, page*bookmarksPageSize)
+	if err != nil {
+		return "", tgbotapi.InlineKeyboardMarkup{}, err
+	}
+
+	text := fmt.Sprintf("🔖 *Your bookmarks* (%d) - page %d of %d\n\n", count, page+1, pages)
+
+	var viewRow []tgbotapi.InlineKeyboardButton
+	for i, question := range questions {
+		number := page*bookmarksPageSize + i + 1
+		text += fmt.Sprintf("%d. _%s_ - %s\n", number, formatLanguageName(question.Language), escapeMarkdown(bookmarkPreview(question.QuestionText)))
+		viewRow = append(viewRow, tgbotapi.NewInlineKeyboardButtonData(strconv.Itoa(number), fmt.Sprintf("bookmark:view:%d", question.ID)))
+	}
+
+	text += "\n_Press a number to see the question with its answer._"
+
+	rows := [][]tgbotapi.InlineKeyboardButton{viewRow}
+
+	var navigationRow []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		navigationRow = append(navigationRow, tgbotapi.NewInlineKeyboardButtonData("◀️ Previous", fmt.Sprintf("bookmark:page:%d", page-1)))
+	}
+	if page < pages-1 {
+		navigationRow = append(navigationRow, tgbotapi.NewInlineKeyboardButtonData("Next ▶️", fmt.Sprintf("bookmark:page:%d", page+1)))
+	}
+	if len(navigationRow) > 0 {
+		rows = append(rows, navigationRow)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📝 Quiz My Bookmarks", "bookmark:quiz"),
+	))
+
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...), nil
+}
+
+// bookmarkPreview shortens a question to the first line of its text for the bookmark list
+func bookmarkPreview(questionText string) string {
+	preview := strings.TrimSpace(strings.SplitN(strings.TrimSpace(questionText), "\n", 2)[0])
+	preview = strings.NewReplacer("`", "", "*", "", "_", "").Replace(preview)
+
+	if runes := []rune(preview); len(runes) > bookmarkPreviewLength {
+		preview = strings.TrimSpace(string(runes[:bookmarkPreviewLength])) + "…"
+	}
+	return preview
+}
+
+// sendBookmark sends a bookmarked question with its correct answer and explanation
+func (b *Bot) sendBookmark(ctx context.Context, chatID int64, questionID int) {
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't retrieve the question. Please try again later.", nil)
+		return
+	}
+
+	text := fmt.Sprintf("🔖 *%s - %s*\n\n%s", formatLanguageName(question.Language), question.Category, question.QuestionText)
+	if usesLetteredOptions(question) {
+		// Bookmarks have no session, so the options keep their original order
+		text += "\n\n" + formatLetteredOptions(&models.QuizSession{}, question)
+	}
+	text += fmt.Sprintf("\n\n*Answer:* %s\n\n*Explanation:*\n%s", formatCorrectAnswer(question), question.Explanation)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🗑 Remove Bookmark", fmt.Sprintf("bookmark:remove:%d", question.ID)),
+	))
+	b.send(msg)
+}
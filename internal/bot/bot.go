@@ -1,11 +1,19 @@
 package bot
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/amiosamu/interview-match-bot/internal/config"
+	"github.com/amiosamu/interview-match-bot/internal/dispatcher"
+	"github.com/amiosamu/interview-match-bot/internal/logging"
 	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/amiosamu/interview-match-bot/internal/monitoring"
+	"github.com/amiosamu/interview-match-bot/internal/sender"
 	"github.com/amiosamu/interview-match-bot/internal/service"
 	"github.com/amiosamu/interview-match-bot/internal/store"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -13,79 +21,337 @@ import (
 
 // Bot represents the interview bot application
 type Bot struct {
-	api         *tgbotapi.BotAPI
-	db          *sql.DB
-	userStore   *store.UserStore
-	quizService *service.QuizService
+	client             *tgbotapi.BotAPI // receives updates; nil when the bot only handles them, e.g. in tests
+	api                Messenger        // the outbox, for requests whose result is needed right away
+	outbox             *sender.Sender   // queues what the bot sends within the rate limits
+	self               tgbotapi.User    // the bot's own account
+	db                 *sql.DB
+	userStore          *store.UserStore
+	quizService        *service.QuizService
+	duelService        *service.DuelService
+	profileService     *service.UserProfileService
+	dailyService       *service.DailyService
+	achievementService *service.AchievementService
+	bookmarkService    *service.BookmarkService
+	flashcardService   *service.FlashcardService
+	dispatcher         *dispatcher.Dispatcher // runs updates concurrently, in order per chat
+	timers             map[int]*questionTimer // countdowns of timed quiz questions by session ID
+	timersMutex        sync.Mutex
+	memberCounts       map[int]int // member counts of group chats by quiz session ID, looked up once per quiz
+	memberCountsMutex  sync.Mutex
+	quiz               config.Quiz // quiz length and the pauses between questions
 	// These services would be added when implementing other features
 	// analyticsService  *service.AnalyticsService
 	// moderationService *service.ModerationService
 }
 
-// NewBot creates a new Bot instance
-func NewBot(token string, db *sql.DB) (*Bot, error) {
-	api, err := tgbotapi.NewBotAPI(token)
+// Features that keep their own tables instead of using the quiz repository.
+// They are unavailable when the bot runs without a database.
+var (
+	databaseCommands         = map[string]bool{"duel": true, "daily": true, "achievements": true, "bookmarks": true}
+	databaseCallbackPrefixes = []string{"bookmark:", "flashcard:", "daily:", "duel:"}
+)
+
+const databaseUnavailableText = "Sorry, this feature isn't available right now."
+
+// NewBot creates a new Bot instance.
+// Quizzes are stored in quizRepository. db may be nil, then the features that keep
+// their own tables, such as duels, daily challenges and bookmarks, are unavailable.
+func NewBot(cfg *config.Config, db *sql.DB, quizRepository service.QuizRepository) (*Bot, error) {
+	api, err := tgbotapi.NewBotAPI(cfg.TelegramToken)
 	if err != nil {
 		return nil, err
 	}
 
+	// Failed requests are counted in the metrics
+	b := newBot(monitoring.InstrumentAPI(api), sender.DefaultLimits, api.Self, db, quizRepository, cfg)
+	b.client = api
+	monitoring.RegisterSenderStats(b.outbox)
+	b.outbox.OnBlocked(func(chatID int64) {
+		b.dispatcher.Submit(chatID, func(ctx context.Context) {
+			b.deactivateUser(ctx, chatID)
+		})
+	})
+	return b, nil
+}
+
+// newBot creates a Bot that talks to Telegram through api, sending within limits.
+// It doesn't receive updates by itself; they are passed to handleUpdate.
+func newBot(api Messenger, limits sender.Limits, self tgbotapi.User, db *sql.DB, quizRepository service.QuizRepository, cfg *config.Config) *Bot {
+	outbox := sender.New(api, limits)
 	return &Bot{
-		api:         api,
-		db:          db,
-		userStore:   store.NewUserStore(),
-		quizService: service.NewQuizService(db),
-	}, nil
+		api:                outbox,
+		outbox:             outbox,
+		self:               self,
+		db:                 db,
+		userStore:          store.NewUserStore(),
+		quizService:        service.NewQuizService(quizRepository),
+		duelService:        service.NewDuelService(db),
+		profileService:     service.NewUserProfileService(db),
+		dailyService:       service.NewDailyService(db),
+		achievementService: service.NewAchievementService(db),
+		bookmarkService:    service.NewBookmarkService(db),
+		flashcardService:   service.NewFlashcardService(db),
+		dispatcher:         dispatcher.New(cfg.Updates.Workers, cfg.Updates.Timeout),
+		timers:             make(map[int]*questionTimer),
+		memberCounts:       make(map[int]int),
+		quiz:               cfg.Quiz,
+	}
 }
 
-// Start begins listening for updates
-func (b *Bot) Start() {
-	log.Printf("Authorized on account %s", b.api.Self.UserName)
+// Start begins long polling for updates and returns once ctx is done.
+// Updates received so far keep being handled until Shutdown is called.
+func (b *Bot) Start(ctx context.Context) {
+	slog.Info("Authorized", "bot", b.self.UserName)
+
+	// Telegram doesn't answer polling requests while a webhook is set, e.g. after running in webhook mode
+	if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		slog.ErrorContext(ctx, "Error deleting webhook", "error", err)
+	}
 
 	u := tgbotapi.NewUpdate(0)
 	u.Timeout = 60
 
-	updates := b.api.GetUpdatesChan(u)
+	updates := b.client.GetUpdatesChan(u)
+
+	if b.db != nil {
+		go b.runDailyBroadcast(ctx)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Updates Telegram sent but we didn't read are delivered again after a restart
+			b.client.StopReceivingUpdates()
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+			b.dispatchUpdate(update)
+		}
+	}
+}
+
+// Shutdown waits until the updates received so far and the follow-ups they scheduled are handled,
+// so their replies are sent. If ctx is done first, the handlers still running are cancelled.
+func (b *Bot) Shutdown(ctx context.Context) error {
+	err := b.dispatcher.Shutdown(ctx)
+
+	// The handlers that ran last may have left messages in the outbox
+	if outboxErr := b.outbox.Shutdown(ctx); err == nil {
+		err = outboxErr
+	}
+
+	stats := b.outbox.Stats()
+	slog.Info("Sent messages", "sent", stats.Sent, "retried", stats.Retried, "dropped", stats.Dropped,
+		"failed", stats.Failed, "blocked", stats.Blocked)
+
+	return err
+}
+
+// dispatchUpdate queues an update behind the other updates of its chat
+func (b *Bot) dispatchUpdate(update tgbotapi.Update) {
+	monitoring.Updates.WithLabelValues(updateType(update)).Inc()
+
+	key, ok := updateKey(update)
+	if !ok {
+		return
+	}
+
+	b.dispatcher.Submit(key, func(ctx context.Context) {
+		b.handleUpdate(ctx, update)
+	})
+}
+
+// updateType labels an update in the update metrics
+func updateType(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		return "command"
+	case update.Message != nil:
+		return "message"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.PollAnswer != nil:
+		return "poll_answer"
+	}
+	return "other"
+}
+
+// updateKey returns the chat an update belongs to, which orders the updates handled by the dispatcher.
+// Poll answers carry no chat, but quiz polls are only sent in private chats, where the chat ID is the user ID.
+func updateKey(update tgbotapi.Update) (int64, bool) {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID, true
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID, true
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID, true
+	case update.PollAnswer != nil:
+		return update.PollAnswer.User.ID, true
+	}
+	return 0, false
+}
+
+// handleUpdate handles a single update.
+// The lines the handlers log carry the update's ID, its user and chat, and the command or button pressed.
+func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
+	ctx = logging.With(ctx, updateFields(update)...)
+	defer observeCommand(update, time.Now())
+
+	if update.Message != nil {
+		b.handleMessage(ctx, update.Message)
+	} else if update.CallbackQuery != nil {
+		b.handleCallbackQuery(ctx, update.CallbackQuery)
+	} else if update.PollAnswer != nil {
+		b.handlePollAnswer(ctx, update.PollAnswer)
+	}
+}
+
+// updateFields returns the fields logged with the lines about an update
+func updateFields(update tgbotapi.Update) []any {
+	fields := []any{"update_id", update.UpdateID}
+
+	switch {
+	case update.Message != nil:
+		fields = append(fields, "chat_id", update.Message.Chat.ID)
+		if update.Message.From != nil {
+			fields = append(fields, "user_id", update.Message.From.ID)
+		}
+		if update.Message.IsCommand() {
+			fields = append(fields, "command", "/"+update.Message.Command())
+		}
+	case update.CallbackQuery != nil:
+		fields = append(fields, "user_id", update.CallbackQuery.From.ID, "command", callbackAction(update.CallbackQuery.Data))
+		if update.CallbackQuery.Message != nil {
+			fields = append(fields, "chat_id", update.CallbackQuery.Message.Chat.ID)
+		}
+	case update.PollAnswer != nil:
+		fields = append(fields, "user_id", update.PollAnswer.User.ID)
+	}
+
+	return fields
+}
+
+// callbackAction returns the action of a button's callback data without its arguments, e.g. quiz:answer
+func callbackAction(data string) string {
+	parts := strings.SplitN(data, ":", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, ":")
+}
 
-	for update := range updates {
-		if update.Message != nil {
-			b.handleMessage(update.Message)
-		} else if update.CallbackQuery != nil {
-			b.handleCallbackQuery(update.CallbackQuery)
+// Commands and button prefixes timed in the command metrics; others are timed as unknown
+var (
+	knownCommands = map[string]bool{
+		"start": true, "help": true, "prepare": true, "duel": true, "leaderboard": true,
+		"daily": true, "stats": true, "achievements": true, "bookmarks": true,
+	}
+	knownCallbackPrefixes = map[string]bool{
+		"category": true, "level": true, "quiz": true, "flashcard": true, "group": true,
+		"daily": true, "leaderboard": true, "duel": true, "main": true, "bookmark": true, "interview": true,
+	}
+)
+
+// observeCommand records how long handling an update that started at start took.
+// Updates are labelled by command or button prefix, e.g. /prepare or quiz, from a fixed set
+// so that users can't add labels by sending made-up commands or callback data.
+func observeCommand(update tgbotapi.Update, start time.Time) {
+	command := "other"
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		command = "/unknown"
+		if knownCommands[update.Message.Command()] {
+			command = "/" + update.Message.Command()
+		}
+	case update.Message != nil:
+		command = "text"
+	case update.CallbackQuery != nil:
+		command = "unknown"
+		if prefix, _, _ := strings.Cut(update.CallbackQuery.Data, ":"); knownCallbackPrefixes[prefix] {
+			command = prefix
 		}
+	case update.PollAnswer != nil:
+		command = "poll_answer"
 	}
+
+	monitoring.CommandDuration.WithLabelValues(command).Observe(time.Since(start).Seconds())
 }
 
 // handleMessage processes incoming messages
-func (b *Bot) handleMessage(message *tgbotapi.Message) {
+func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) {
 	// Save or update user information
-	b.saveUserInfo(message.From)
+	b.saveUserInfo(ctx, message.From)
 
 	// Handle commands
 	if message.IsCommand() {
+		if databaseCommands[message.Command()] && !b.requireDatabase(message.Chat.ID) {
+			return
+		}
+
 		switch message.Command() {
 		case "start":
-			b.handleStartCommand(message)
+			b.handleStartCommand(ctx, message)
 		case "help":
 			b.handleHelpCommand(message)
 		case "prepare":
-			b.handlePrepareCommand(message)
+			b.handlePrepareCommand(ctx, message)
+		case "duel":
+			b.handleDuelCommand(ctx, message)
+		case "leaderboard":
+			b.handleLeaderboardCommand(ctx, message)
+		case "daily":
+			b.handleDailyCommand(ctx, message)
+		case "stats":
+			b.handleStatsCommand(ctx, message)
+		case "achievements":
+			b.handleAchievementsCommand(ctx, message)
+		case "bookmarks":
+			b.handleBookmarksCommand(ctx, message)
 		default:
 			b.sendMessage(message.Chat.ID, "Unknown command. Type /start to begin or /help for assistance.", nil)
 		}
 		return
 	}
 
-	// For non-command messages, just prompt the user to use the commands
+	// Group members talk among themselves, only commands are for the bot
+	if !message.Chat.IsPrivate() {
+		return
+	}
+
+	// Plain messages may answer a free-text quiz question
+	if b.handleQuizTextAnswer(ctx, message) {
+		return
+	}
+
+	// For other non-command messages, just prompt the user to use the commands
 	b.sendMessage(message.Chat.ID, "Please use the buttons or type /start to begin.", nil)
 }
 
 // handleCallbackQuery processes button presses
-func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
+func (b *Bot) handleCallbackQuery(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	// Features that keep their own tables need a database
+	for _, prefix := range databaseCallbackPrefixes {
+		if strings.HasPrefix(query.Data, prefix) && b.db == nil {
+			b.request(tgbotapi.NewCallback(query.ID, databaseUnavailableText))
+			return
+		}
+	}
+
+	// Bookmark buttons are confirmed in the callback answer instead of a new message
+	if strings.HasPrefix(query.Data, "bookmark:") {
+		b.handleBookmarkCallback(ctx, query)
+		return
+	}
+
 	// Always answer the callback query to stop the loading indicator
 	callback := tgbotapi.NewCallback(query.ID, "")
-	b.api.Request(callback)
+	b.request(callback)
 
-	user := b.saveUserInfo(query.From)
+	user := b.saveUserInfo(ctx, query.From)
 
 	// Parse the callback data
 	data := query.Data
@@ -98,7 +364,7 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 		// Now ask for the level
 		msg := tgbotapi.NewMessage(query.Message.Chat.ID, "Great! Now select your experience level:")
 		msg.ReplyMarkup = CreateLevelsKeyboard()
-		b.api.Send(msg)
+		b.send(msg)
 
 	} else if strings.HasPrefix(data, "level:") {
 		level := strings.TrimPrefix(data, "level:")
@@ -112,10 +378,28 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 		b.sendMessage(query.Message.Chat.ID, confirmMessage, nil)
 
 		// Find matches
-		b.notifyMatches(user)
+		b.notifyMatches(ctx, user)
 	} else if strings.HasPrefix(data, "quiz:") {
 		// Handle quiz-related callbacks
-		b.handleQuizCallback(query)
+		b.handleQuizCallback(ctx, query)
+	} else if strings.HasPrefix(data, "flashcard:") {
+		// Handle flashcard callbacks
+		b.handleFlashcardCallback(ctx, query)
+	} else if strings.HasPrefix(data, "group:") {
+		// Handle group quiz callbacks
+		b.handleGroupCallback(ctx, query)
+	} else if strings.HasPrefix(data, "daily:") {
+		// Handle daily challenge callbacks
+		b.handleDailyCallback(ctx, query)
+	} else if strings.HasPrefix(data, "leaderboard:") {
+		// Handle leaderboard callbacks
+		b.handleLeaderboardCallback(ctx, query)
+	} else if strings.HasPrefix(data, "duel:") {
+		// Handle quiz duel callbacks
+		b.handleDuelCallback(ctx, query)
+	} else if strings.HasPrefix(data, "interview:") {
+		// Handle mock interview callbacks
+		b.handleInterviewCallback(ctx, query, user)
 	} else if strings.HasPrefix(data, "main:") {
 		// Handle main menu callbacks
 		if data == "main:menu" {
@@ -134,9 +418,12 @@ func (b *Bot) handleCallbackQuery(query *tgbotapi.CallbackQuery) {
 }
 
 // saveUserInfo stores or updates user information
-func (b *Bot) saveUserInfo(tgUser *tgbotapi.User) *models.User {
+func (b *Bot) saveUserInfo(ctx context.Context, tgUser *tgbotapi.User) *models.User {
 	user, exists := b.userStore.GetUser(tgUser.ID)
 
+	// Users who blocked the bot and talk to it again can be messaged again
+	reactivated := exists && b.userStore.SetUserInactive(user.ID, false)
+
 	if !exists {
 		user = &models.User{
 			ID:        tgUser.ID,
@@ -147,18 +434,61 @@ func (b *Bot) saveUserInfo(tgUser *tgbotapi.User) *models.User {
 		b.userStore.SaveUser(user)
 	}
 
+	// Keep the names for leaderboards and other users' views
+	if (!exists || reactivated) && b.db != nil {
+		if err := b.profileService.SaveProfile(ctx, user); err != nil {
+			slog.ErrorContext(ctx, "Error saving user profile", "error", err)
+		}
+	}
+
 	return user
 }
 
+// deactivateUser stops messaging a user who blocked the bot or deleted their account
+// until they talk to the bot again. Group chats the bot was removed from are ignored.
+func (b *Bot) deactivateUser(ctx context.Context, chatID int64) {
+	// In private chats the chat ID is the user ID
+	if chatID < 0 {
+		return
+	}
+
+	slog.Info("User blocked the bot, marking them inactive", "user_id", chatID)
+	b.userStore.SetUserInactive(chatID, true)
+
+	if b.db != nil {
+		if err := b.profileService.MarkBlocked(ctx, chatID); err != nil {
+			slog.ErrorContext(ctx, "Error marking user as blocked", "user_id", chatID, "error", err)
+		}
+	}
+}
+
+// requireDatabase tells the chat that a feature is unavailable when the bot runs without a database.
+// It returns whether the feature can be used.
+func (b *Bot) requireDatabase(chatID int64) bool {
+	if b.db != nil {
+		return true
+	}
+	b.sendMessage(chatID, databaseUnavailableText, nil)
+	return false
+}
+
 // sendMessage sends a message to a chat
 func (b *Bot) sendMessage(chatID int64, text string, markup interface{}) {
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ReplyMarkup = markup
-	b.api.Send(msg)
+	b.send(msg)
+}
+
+// sendMarkdown sends a message formatted with Markdown. Text from users must be escaped with escapeMarkdown.
+func (b *Bot) sendMarkdown(chatID int64, text string, markup interface{}) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = markup
+	b.send(msg)
 }
 
 // notifyMatches notifies users about matches
-func (b *Bot) notifyMatches(user *models.User) {
+func (b *Bot) notifyMatches(ctx context.Context, user *models.User) {
 	matches := b.userStore.FindMatches(user.ID, user.Field, user.Level)
 
 	if len(matches) == 0 {
@@ -169,16 +499,23 @@ func (b *Bot) notifyMatches(user *models.User) {
 	for _, match := range matches {
 		messageText := "I found a match! User " + match.DisplayName() + " is also looking for " +
 			user.Field + " " + user.Level + " positions."
-		b.sendMessage(user.ID, messageText, nil)
+		b.sendMessage(user.ID, messageText, matchKeyboard(match.ID))
 
 		// Also notify the matched user
 		matchMessageText := "I found a match! User " + user.DisplayName() + " is also looking for " +
 			user.Field + " " + user.Level + " positions."
-		b.sendMessage(match.ID, matchMessageText, nil)
+		b.sendMessage(match.ID, matchMessageText, matchKeyboard(user.ID))
+		monitoring.MatchProposals.Inc()
 	}
 }
 
+// CheckTelegram checks that Telegram answers the bot's requests, for the readiness check
+func (b *Bot) CheckTelegram(ctx context.Context) error {
+	_, err := b.client.GetMe()
+	return err
+}
+
 // Debug enables debug mode for the bot
 func (b *Bot) Debug(enable bool) {
-	b.api.Debug = enable
+	b.client.Debug = enable
 }
@@ -0,0 +1,248 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/config"
+	"github.com/amiosamu/interview-match-bot/internal/sender"
+	"github.com/amiosamu/interview-match-bot/internal/service"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// lastTestUserID makes the users of the tests unique
+var lastTestUserID int64 = 8_000_000_000
+
+// testBot is a bot that sends everything to a fake messenger, and the user chatting with it privately.
+// The user name has an underscore, so messages that show it unescaped in Markdown fail to send.
+type testBot struct {
+	*Bot
+	messenger *fakeMessenger
+	user      tgbotapi.User
+	seen      int // messages the steps run so far have looked at
+}
+
+// testSendLimits let the tests send without waiting for the rate limits
+var testSendLimits = sender.Limits{
+	GlobalRate:  1000,
+	GlobalBurst: 1000,
+	ChatRate:    1000,
+	ChatBurst:   1000,
+	GroupRate:   1000,
+	GroupBurst:  1000,
+	MaxWait:     time.Second,
+}
+
+// newTestBot creates a bot for a new user that keeps its quizzes in repo and runs without a database.
+// Quiz questions follow each other without pauses.
+func newTestBot(t *testing.T, repo service.QuizRepository) *testBot {
+	t.Helper()
+
+	messenger := &fakeMessenger{}
+	cfg := config.Default()
+	cfg.Quiz.FirstQuestionDelay = 0
+	cfg.Quiz.NextQuestionDelay = 0
+	b := newBot(messenger, testSendLimits, tgbotapi.User{ID: 1, IsBot: true, UserName: "test_bot"}, nil, repo, cfg)
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := b.Shutdown(ctx); err != nil {
+			t.Errorf("shutting down: %v", err)
+		}
+	})
+
+	userID := atomic.AddInt64(&lastTestUserID, 1)
+	return &testBot{
+		Bot:       b,
+		messenger: messenger,
+		user:      tgbotapi.User{ID: userID, FirstName: "Test", UserName: fmt.Sprintf("test_%d", userID)},
+	}
+}
+
+// step is one turn of a conversation: the user sends a message or presses a button,
+// and the bot answers with a message containing want
+type step struct {
+	send        string   // text of a message the user sends, commands included
+	press       string   // text of a button the user presses on the newest message that has it
+	twice       bool     // the button is pressed twice in a row, like a double tap
+	want        string   // text the reply must contain; without send or press, the next message the bot sends
+	wantButtons []string // buttons the reply must have
+}
+
+// dispatchUpdate hands an update to the bot once the messages it sent so far went out.
+// What the bot does after a message was sent, like starting the countdown of a question,
+// then runs before the update, as it does when the user answers what they see.
+func (b *testBot) dispatchUpdate(update tgbotapi.Update) {
+	for deadline := time.Now().Add(5 * time.Second); b.outbox.Pending() > 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	b.Bot.dispatchUpdate(update)
+}
+
+// run plays a conversation with the bot and checks its replies
+func (b *testBot) run(t *testing.T, steps []step) {
+	t.Helper()
+
+	for _, step := range steps {
+		switch {
+		case step.send != "":
+			b.dispatchUpdate(b.messageUpdate(step.send))
+		case step.press != "":
+			message, data := b.messenger.lastButton(t, step.press)
+			b.dispatchUpdate(b.callbackUpdate(message, data))
+			if step.twice {
+				b.dispatchUpdate(b.callbackUpdate(message, data))
+			}
+		}
+
+		var reply sentMessage
+		reply, b.seen = b.messenger.waitForMessage(t, b.seen, step.want)
+
+		for _, button := range step.wantButtons {
+			if _, ok := reply.button(button); !ok {
+				t.Errorf("reply %q has no %q button", reply.Text, button)
+			}
+		}
+	}
+}
+
+// messageUpdate is an update with a message the user sent to the bot in their private chat
+func (b *testBot) messageUpdate(text string) tgbotapi.Update {
+	message := &tgbotapi.Message{
+		MessageID: int(time.Now().UnixNano() % 1_000_000),
+		From:      &b.user,
+		Chat:      &tgbotapi.Chat{ID: b.user.ID, Type: "private"},
+		Date:      int(time.Now().Unix()),
+		Text:      text,
+	}
+
+	if strings.HasPrefix(text, "/") {
+		command, _, _ := strings.Cut(text, " ")
+		message.Entities = []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+	}
+
+	return tgbotapi.Update{Message: message}
+}
+
+// callbackUpdate is an update with a button press on a message the bot sent
+func (b *testBot) callbackUpdate(message sentMessage, data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:   fmt.Sprintf("callback-%d", time.Now().UnixNano()),
+		From: &b.user,
+		Message: &tgbotapi.Message{
+			MessageID: message.MessageID,
+			Chat:      &tgbotapi.Chat{ID: message.ChatID, Type: "private"},
+			Text:      message.Text,
+		},
+		Data: data,
+	}}
+}
+
+func TestConversations(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "start, category and level",
+			steps: []step{
+				{send: "/start", want: "Please select your field of interest", wantButtons: []string{"Algorithms", "Go", "QA"}},
+				{press: "Go", want: "Now select your experience level", wantButtons: ExperienceLevels},
+				{press: "Junior", want: "You selected: Go - Junior"},
+			},
+		},
+		{
+			name: "start again with another category",
+			steps: []step{
+				{send: "/start", want: "Please select your field of interest"},
+				{press: "SystemDesign", want: "Now select your experience level"},
+				{press: "Senior", want: "You selected: SystemDesign - Senior"},
+			},
+		},
+		{
+			name: "help",
+			steps: []step{
+				{send: "/help", want: "Interview Match Bot Help"},
+			},
+		},
+		{
+			name: "unknown command",
+			steps: []step{
+				{send: "/unknown", want: "Unknown command"},
+			},
+		},
+		{
+			name: "plain message",
+			steps: []step{
+				{send: "hello", want: "Please use the buttons or type /start to begin."},
+			},
+		},
+		{
+			name: "feature needing a database",
+			steps: []step{
+				{send: "/daily", want: "this feature isn't available"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newTestBot(t, service.NewMemoryQuizRepository()).run(t, test.steps)
+		})
+	}
+}
+
+func TestUpdateKey(t *testing.T) {
+	user := &tgbotapi.User{ID: 42}
+	group := &tgbotapi.Chat{ID: -100, Type: "group"}
+
+	tests := []struct {
+		name    string
+		update  tgbotapi.Update
+		wantKey int64
+		wantOK  bool
+	}{
+		{
+			name:    "message",
+			update:  tgbotapi.Update{Message: &tgbotapi.Message{From: user, Chat: group}},
+			wantKey: -100,
+			wantOK:  true,
+		},
+		{
+			name:    "callback query on a message",
+			update:  tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{From: user, Message: &tgbotapi.Message{Chat: group}}},
+			wantKey: -100,
+			wantOK:  true,
+		},
+		{
+			name:    "callback query without a message",
+			update:  tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{From: user}},
+			wantKey: 42,
+			wantOK:  true,
+		},
+		{
+			name:    "poll answer",
+			update:  tgbotapi.Update{PollAnswer: &tgbotapi.PollAnswer{User: *user}},
+			wantKey: 42,
+			wantOK:  true,
+		},
+		{
+			name:   "other update",
+			update: tgbotapi.Update{EditedMessage: &tgbotapi.Message{From: user, Chat: group}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, ok := updateKey(test.update)
+			if key != test.wantKey || ok != test.wantOK {
+				t.Errorf("updateKey() = %d, %v, want %d, %v", key, ok, test.wantKey, test.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/logging"
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dailyBroadcastHour is the hour (UTC) the daily challenge is sent to subscribers
+const dailyBroadcastHour = 9
+
+// dailyDateFormat is the format of challenge days in callback data
+const dailyDateFormat = "2006-01-02"
+
+// handleDailyCommand asks which language's daily challenge to show
+func (b *Bot) handleDailyCommand(ctx context.Context, message *tgbotapi.Message) {
+	user := b.saveUserInfo(ctx, message.From)
+
+	keyboard := b.quizLanguageKeyboard(ctx, func(language string) string {
+		return "daily:lang:" + language
+	})
+
+	subscribed, err := b.profileService.GetDailyLanguage(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving daily challenge setting", "error", err)
+	}
+
+	if subscribed != "" {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔕 Stop Daily Reminders", "daily:unsubscribe"),
+		))
+	}
+
+	b.sendMessage(message.Chat.ID, "📅 Choose a language for today's daily challenge:", keyboard)
+}
+
+// handleDailyCallback processes daily challenge button actions
+func (b *Bot) handleDailyCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	data := query.Data
+	chatID := query.Message.Chat.ID
+	user := b.saveUserInfo(ctx, query.From)
+
+	switch {
+	case strings.HasPrefix(data, "daily:lang:"):
+		if msg, _ := b.dailyChallengeMessage(ctx, chatID, strings.TrimPrefix(data, "daily:lang:"), time.Now()); msg != nil {
+			b.send(*msg)
+		}
+
+	case strings.HasPrefix(data, "daily:subscribe:"):
+		language := strings.TrimPrefix(data, "daily:subscribe:")
+		if err := b.profileService.SetDailyLanguage(ctx, user.ID, language); err != nil {
+			slog.ErrorContext(ctx, "Error updating daily challenge setting", "error", err)
+			b.sendMessage(chatID, "Sorry, I couldn't update your setting. Please try again later.", nil)
+			return
+		}
+		b.sendMessage(chatID, fmt.Sprintf("🔔 You'll get the %s daily challenge every day at %02d:00 UTC. Type /daily to stop the reminders.",
+			formatLanguageName(language), dailyBroadcastHour), nil)
+
+	case data == "daily:unsubscribe":
+		if err := b.profileService.SetDailyLanguage(ctx, user.ID, ""); err != nil {
+			slog.ErrorContext(ctx, "Error updating daily challenge setting", "error", err)
+			b.sendMessage(chatID, "Sorry, I couldn't update your setting. Please try again later.", nil)
+			return
+		}
+		b.sendMessage(chatID, "🔕 You won't get daily challenge reminders anymore. Type /daily to play anyway.", nil)
+
+	case strings.HasPrefix(data, "daily:answer:"):
+		b.processDailyAnswer(ctx, query, user)
+
+	default:
+		b.sendMessage(chatID, "Invalid option. Please try again.", nil)
+	}
+}
+
+// dailyChallengeMessage creates the message with the daily challenge question of a language for the day of now.
+// If there is no challenge for the language or it couldn't be loaded, it tells the user so and returns nil,
+// together with the error in the latter case.
+func (b *Bot) dailyChallengeMessage(ctx context.Context, chatID int64, language string, now time.Time) (*tgbotapi.MessageConfig, error) {
+	day := models.ChallengeDay(now)
+
+	question, err := b.dailyService.GetDailyQuestion(ctx, language, day)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching daily question", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't load the daily challenge. Please try again later.", nil)
+		return nil, err
+	}
+
+	if question == nil {
+		b.sendMessage(chatID, fmt.Sprintf("Sorry, there is no daily challenge for %s yet. Please try another language.", formatLanguageName(language)), nil)
+		return nil, nil
+	}
+
+	messageText := fmt.Sprintf("📅 *Daily Challenge - %s*\n_%s_\n\n%s", formatLanguageName(language), day.Format("January 2"), question.QuestionText)
+
+	// The daily question has no session, so its options keep their original order
+	noSession := &models.QuizSession{}
+	lettered := usesLetteredOptions(question)
+	if lettered {
+		messageText += "\n\n" + formatLetteredOptions(noSession, question)
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for index, option := range question.AnswerOptions {
+		callbackData := fmt.Sprintf("daily:answer:%s:%s:%d:%d", day.Format(dailyDateFormat), language, question.ID, index)
+		button := tgbotapi.NewInlineKeyboardButtonData(option, callbackData)
+
+		if !lettered {
+			rows = append(rows, []tgbotapi.InlineKeyboardButton{button})
+			continue
+		}
+
+		button.Text = optionLetter(index)
+		if index%lettersPerRow == 0 {
+			rows = append(rows, nil)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], button)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, messageText)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &msg, nil
+}
+
+// processDailyAnswer grades an answer to a daily challenge and shows the user's streak.
+// The callback data is daily:answer:<day>:<language>:<question ID>:<option index>.
+func (b *Bot) processDailyAnswer(ctx context.Context, query *tgbotapi.CallbackQuery, user *models.User) {
+	chatID := query.Message.Chat.ID
+
+	parts := strings.Split(query.Data, ":")
+	if len(parts) != 6 {
+		b.sendMessage(chatID, "Invalid option. Please try again.", nil)
+		return
+	}
+
+	day, err := time.Parse(dailyDateFormat, parts[2])
+	language := parts[3]
+	questionID, idErr := strconv.Atoi(parts[4])
+	answerIndex, indexErr := strconv.Atoi(parts[5])
+	if err != nil || idErr != nil || indexErr != nil {
+		b.sendMessage(chatID, "Invalid answer. Please try again.", nil)
+		return
+	}
+
+	now := time.Now()
+	if !day.Equal(models.ChallengeDay(now)) {
+		b.sendMessage(chatID, "⌛ This daily challenge has ended. Type /daily for today's question.", nil)
+		return
+	}
+
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't process your answer. Please try again later.", nil)
+		return
+	}
+
+	if answerIndex < 0 || answerIndex >= len(question.AnswerOptions) {
+		b.sendMessage(chatID, "Invalid answer selection. Please try again.", nil)
+		return
+	}
+
+	isCorrect := question.IsCorrectOption(answerIndex)
+	recorded, err := b.dailyService.RecordDailyAnswer(ctx, user.ID, day, language, question.ID, answerIndex, isCorrect)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error recording daily answer", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't process your answer. Please try again later.", nil)
+		return
+	}
+
+	if !recorded {
+		b.sendMessage(chatID, fmt.Sprintf("You already answered today's %s challenge. Come back tomorrow!", formatLanguageName(language)), nil)
+		return
+	}
+
+	// Drop the answer buttons from the question
+	b.send(tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	}))
+
+	var feedbackMessage string
+	if isCorrect {
+		feedbackMessage = "✅ *Correct!*\n\n"
+	} else {
+		feedbackMessage = fmt.Sprintf("❌ *Incorrect*\n\nThe correct answer is: %s\n\n", formatCorrectAnswer(question))
+	}
+	feedbackMessage += "*Explanation:*\n" + question.Explanation
+
+	streak, err := b.dailyService.GetDailyStreak(ctx, user.ID, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving daily streak", "error", err)
+	} else {
+		feedbackMessage += "\n\n🔥 " + formatDailyStreak(streak)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, feedbackMessage)
+	msg.ParseMode = "Markdown"
+
+	// Offer the daily reminder to users who don't get it yet
+	keyboard := bookmarkKeyboard(question.ID)
+	subscribed, err := b.profileService.GetDailyLanguage(ctx, user.ID)
+	if err == nil && subscribed == "" {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔔 Remind Me Every Day", "daily:subscribe:"+language),
+		))
+	}
+	msg.ReplyMarkup = keyboard
+
+	b.send(msg)
+
+	b.unlockAchievements(ctx, chatID, user.ID, models.EventDailyAnswered)
+}
+
+// formatDailyStreak describes a user's daily challenge streak
+func formatDailyStreak(streak models.DailyStreak) string {
+	if streak.Current == 0 {
+		return fmt.Sprintf("*Daily streak:* none right now (longest: %s). Type /daily to start a new one!", formatDays(streak.Longest))
+	}
+	return fmt.Sprintf("*Daily streak:* %s (longest: %s)", formatDays(streak.Current), formatDays(streak.Longest))
+}
+
+// formatDays formats a number of days
+func formatDays(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// runDailyBroadcast sends the daily challenge to subscribers every day at dailyBroadcastHour
+// until ctx is done
+func (b *Bot) runDailyBroadcast(ctx context.Context) {
+	for {
+		now := time.Now().UTC()
+		next := time.Date(now.Year(), now.Month(), now.Day(), dailyBroadcastHour, 0, 0, 0, time.UTC)
+
+		// Subscribers who didn't get today's challenge yet get it right away,
+		// e.g. when the bot was down at the broadcast hour
+		if !now.Before(next) {
+			b.broadcastDailyChallenge(ctx, now)
+			next = next.AddDate(0, 0, 1)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+	}
+}
+
+// broadcastDailyChallenge sends the day's challenge to every subscriber who didn't get it yet.
+// The challenges are sent one after another from the broadcast's own goroutine rather than
+// on the dispatcher, waiting for each to be sent, so the broadcast doesn't fill up the send queues.
+// Subscribers are only marked once their challenge was sent, so the ones it failed for
+// get it when the bot restarts.
+func (b *Bot) broadcastDailyChallenge(ctx context.Context, now time.Time) {
+	subscribers, err := b.dailyService.GetPendingDailySubscribers(ctx, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving daily subscribers", "error", err)
+		return
+	}
+
+	// Quizzes run in private chats, where the chat ID is the user ID
+	sent := 0
+	for _, subscriber := range subscribers {
+		if ctx.Err() != nil {
+			slog.WarnContext(ctx, "Stopped the daily broadcast", "sent", sent, "subscribers", len(subscribers))
+			return
+		}
+
+		subscriberCtx := logging.With(ctx, "user_id", subscriber.UserID, "chat_id", subscriber.UserID)
+		msg, err := b.dailyChallengeMessage(subscriberCtx, subscriber.UserID, subscriber.Language, now)
+		if err != nil {
+			continue
+		}
+		if msg != nil {
+			if _, err := b.sendAndWait(*msg); err != nil {
+				continue
+			}
+		}
+		if err := b.dailyService.MarkDailySent(ctx, subscriber.UserID, now); err != nil {
+			slog.ErrorContext(subscriberCtx, "Error marking daily challenge as sent", "error", err)
+		}
+		sent++
+	}
+
+	if len(subscribers) > 0 {
+		slog.InfoContext(ctx, "Sent the daily challenge", "sent", sent, "subscribers", len(subscribers))
+	}
+}
@@ -0,0 +1,413 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/amiosamu/interview-match-bot/internal/logging"
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/amiosamu/interview-match-bot/internal/monitoring"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// duelStartPrefix prefixes the /start payload of duel invite links
+const duelStartPrefix = "duel_"
+
+// handleDuelCommand lets the user pick a language for a duel shared as an invite link
+// and offers to challenge their matched partners directly
+func (b *Bot) handleDuelCommand(ctx context.Context, message *tgbotapi.Message) {
+	user := b.saveUserInfo(ctx, message.From)
+	b.sendDuelLanguageSelection(ctx, message.Chat.ID, 0)
+
+	// Matched partners can be challenged without sharing a link
+	matches := b.userStore.FindMatches(user.ID, user.Field, user.Level)
+	if len(matches) == 0 {
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, match := range matches {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚔️ "+match.DisplayName(), fmt.Sprintf("duel:challenge:%d", match.ID)),
+		))
+	}
+
+	b.sendMessage(message.Chat.ID, "Or challenge one of your interview partners directly:", tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// duelChallengeKeyboard offers to challenge a matched partner to a duel
+func duelChallengeKeyboard(partnerID int64) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚔️ Challenge to a quiz duel", fmt.Sprintf("duel:challenge:%d", partnerID)),
+		),
+	)
+}
+
+// sendDuelLanguageSelection asks for the language of a new duel.
+// opponentID is the user to challenge, or 0 to create an invite link.
+func (b *Bot) sendDuelLanguageSelection(ctx context.Context, chatID int64, opponentID int64) {
+	keyboard := b.quizLanguageKeyboard(ctx, func(language string) string {
+		return fmt.Sprintf("duel:lang:%s:%d", language, opponentID)
+	})
+
+	text := "⚔️ Choose a language for your quiz duel. You'll get an invite link to share with your opponent:"
+	if opponentID != 0 {
+		text = fmt.Sprintf("⚔️ Choose a language for your quiz duel with %s:", b.displayName(opponentID))
+	}
+
+	b.sendMessage(chatID, text, keyboard)
+}
+
+// handleDuelCallback processes duel-related button actions
+func (b *Bot) handleDuelCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	data := query.Data
+	chatID := query.Message.Chat.ID
+	user := b.saveUserInfo(ctx, query.From)
+	parts := strings.Split(data, ":")
+
+	switch {
+	case strings.HasPrefix(data, "duel:challenge:") && len(parts) == 3:
+		opponentID, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			b.sendMessage(chatID, "Invalid option. Please try again.", nil)
+			return
+		}
+		b.sendDuelLanguageSelection(ctx, chatID, opponentID)
+
+	case strings.HasPrefix(data, "duel:lang:") && len(parts) == 4:
+		opponentID, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			b.sendMessage(chatID, "Invalid option. Please try again.", nil)
+			return
+		}
+		b.createDuel(ctx, chatID, user, parts[2], opponentID)
+
+	case strings.HasPrefix(data, "duel:accept:") && len(parts) == 3:
+		duelID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			b.sendMessage(chatID, "Invalid duel. Please try again.", nil)
+			return
+		}
+		b.acceptDuel(ctx, chatID, user, duelID)
+
+	case strings.HasPrefix(data, "duel:decline:") && len(parts) == 3:
+		duelID, err := strconv.Atoi(parts[2])
+		if err != nil {
+			b.sendMessage(chatID, "Invalid duel. Please try again.", nil)
+			return
+		}
+		b.declineDuel(ctx, chatID, user, duelID)
+
+	default:
+		b.sendMessage(chatID, "Invalid option. Please try again.", nil)
+	}
+}
+
+// createDuel picks the questions of a new duel and invites the opponent.
+// Without an opponent the challenger gets an invite link to share.
+func (b *Bot) createDuel(ctx context.Context, chatID int64, challenger *models.User, language string, opponentID int64) {
+	if opponentID == challenger.ID {
+		b.sendMessage(chatID, "You can't challenge yourself to a duel.", nil)
+		return
+	}
+
+	questions, err := b.quizService.GetQuestionsByLanguage(ctx, language, b.quiz.QuestionCount)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching questions", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't create the duel. Please try again later.", nil)
+		return
+	}
+
+	if len(questions) == 0 {
+		b.sendMessage(chatID, fmt.Sprintf("Sorry, no questions are available for %s yet. Please try another language.", formatLanguageName(language)), nil)
+		return
+	}
+
+	var questionIDs []int
+	for _, q := range questions {
+		questionIDs = append(questionIDs, q.ID)
+	}
+
+	var opponent *int64
+	if opponentID != 0 {
+		opponent = &opponentID
+	}
+
+	duel, err := b.duelService.CreateDuel(ctx, challenger.ID, opponent, language, questionIDs)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating duel", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't create the duel. Please try again later.", nil)
+		return
+	}
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s%d", b.self.UserName, duelStartPrefix, duel.ID)
+
+	if opponent == nil {
+		b.sendMessage(chatID, fmt.Sprintf("⚔️ Your %s duel is ready! Send this link to your opponent, the duel starts as soon as they accept:\n\n%s",
+			formatLanguageName(language), link), nil)
+		return
+	}
+
+	// Matched partners have talked to the bot, so they can be messaged directly
+	b.sendDuelInvitation(opponentID, duel)
+	b.sendMessage(chatID, fmt.Sprintf("⚔️ Challenge sent to %s! The duel starts as soon as they accept.", b.displayName(opponentID)), nil)
+}
+
+// sendDuelInvitation asks a user to accept or decline a duel
+func (b *Bot) sendDuelInvitation(chatID int64, duel *models.QuizDuel) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Accept", fmt.Sprintf("duel:accept:%d", duel.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("Decline", fmt.Sprintf("duel:decline:%d", duel.ID)),
+		),
+	)
+
+	text := fmt.Sprintf("⚔️ %s challenges you to a %s quiz duel with %d questions! "+
+		"You both get the same questions. The most correct answers wins, and the faster player wins a tie.",
+		b.displayName(duel.ChallengerID), formatLanguageName(duel.Language), len(duel.QuestionIDs))
+
+	b.sendMessage(chatID, text, keyboard)
+}
+
+// handleDuelInviteLink shows the invitation of a duel opened through an invite link
+func (b *Bot) handleDuelInviteLink(ctx context.Context, chatID int64, user *models.User, payload string) {
+	duelID, err := strconv.Atoi(strings.TrimPrefix(payload, duelStartPrefix))
+	if err != nil {
+		b.sendMessage(chatID, "This duel invite link is invalid.", nil)
+		return
+	}
+
+	duel, err := b.duelService.GetDuel(ctx, duelID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving duel", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't load the duel. Please try again later.", nil)
+		return
+	}
+
+	if duel == nil || duel.Status != models.DuelStatusPending {
+		b.sendMessage(chatID, "This duel is no longer available.", nil)
+		return
+	}
+
+	if duel.ChallengerID == user.ID {
+		b.sendMessage(chatID, "This is your own duel. Send the link to your opponent!", nil)
+		return
+	}
+
+	b.sendDuelInvitation(chatID, duel)
+}
+
+// acceptDuel starts a duel and sends both participants their first question
+func (b *Bot) acceptDuel(ctx context.Context, chatID int64, user *models.User, duelID int) {
+	accepted, err := b.duelService.AcceptDuel(ctx, duelID, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error accepting duel", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't start the duel. Please try again later.", nil)
+		return
+	}
+
+	if !accepted {
+		b.sendMessage(chatID, "This duel is no longer available.", nil)
+		return
+	}
+
+	duel, err := b.duelService.GetDuel(ctx, duelID)
+	if err != nil || duel == nil {
+		slog.ErrorContext(ctx, "Error retrieving duel", "duel_id", duelID, "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't start the duel. Please try again later.", nil)
+		return
+	}
+
+	// Quizzes run in private chats, where the chat ID is the user ID.
+	// The challenger's session is started in order with the challenger's own updates.
+	b.dispatcher.Submit(duel.ChallengerID, func(ctx context.Context) {
+		b.startDuelSession(ctx, duel.ChallengerID, duel, user.ID)
+	})
+	b.startDuelSession(ctx, user.ID, duel, duel.ChallengerID)
+}
+
+// startDuelSession creates the quiz session of one duel participant and sends the first question
+func (b *Bot) startDuelSession(ctx context.Context, userID int64, duel *models.QuizDuel, opponentID int64) {
+	// The duel replaces any quiz the user was taking
+	b.abandonActiveQuiz(ctx, userID)
+
+	var questions []*models.QuizQuestion
+	for _, questionID := range duel.QuestionIDs {
+		question, err := b.quizService.GetQuestionByID(ctx, questionID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
+			b.sendMessage(userID, "Sorry, I couldn't start the duel. Please try again later.", nil)
+			return
+		}
+		questions = append(questions, question)
+	}
+
+	session, err := b.quizService.CreateQuizSession(ctx, userID, duel.Language, questions, models.QuizOptions{
+		RenderMode: models.RenderModeKeyboard,
+		DuelID:     &duel.ID,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating duel session", "error", err)
+		b.sendMessage(userID, "Sorry, I couldn't start the duel. Please try again later.", nil)
+		return
+	}
+	monitoring.QuizzesStarted.WithLabelValues(quizKind(session)).Inc()
+
+	b.sendMessage(userID, fmt.Sprintf("⚔️ The %s duel against %s starts now! Good luck!",
+		formatLanguageName(duel.Language), b.displayName(opponentID)), nil)
+	b.sendQuizQuestion(ctx, userID, userID, session)
+}
+
+// declineDuel declines a duel and lets the challenger know
+func (b *Bot) declineDuel(ctx context.Context, chatID int64, user *models.User, duelID int) {
+	duel, err := b.duelService.GetDuel(ctx, duelID)
+	if err != nil || duel == nil {
+		b.sendMessage(chatID, "This duel is no longer available.", nil)
+		return
+	}
+
+	declined, err := b.duelService.DeclineDuel(ctx, duelID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error declining duel", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't decline the duel. Please try again later.", nil)
+		return
+	}
+
+	if !declined {
+		b.sendMessage(chatID, "This duel is no longer available.", nil)
+		return
+	}
+
+	b.sendMessage(chatID, "You declined the duel.", nil)
+	b.sendMarkdown(duel.ChallengerID, fmt.Sprintf("%s declined your %s duel.", escapeMarkdown(user.DisplayName()), formatLanguageName(duel.Language)), nil)
+}
+
+// announceDuelQuestion tells both participants how they did on the question at questionIndex once both answered it
+func (b *Bot) announceDuelQuestion(ctx context.Context, session *models.QuizSession, questionIndex int) {
+	ctx = logging.With(ctx, "session_id", session.ID)
+
+	duelID := *session.DuelID
+	questionID := session.QuestionIDs[questionIndex]
+
+	answers, err := b.duelService.GetDuelAnswers(ctx, duelID, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving duel answers", "error", err)
+		return
+	}
+
+	if len(answers) < 2 {
+		return
+	}
+
+	questionNumber := questionIndex + 1
+	claimed, err := b.duelService.ClaimQuestionAnnouncement(ctx, duelID, questionNumber)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error claiming duel announcement", "error", err)
+		return
+	}
+
+	// The other participant's answer already triggered the announcement
+	if !claimed {
+		return
+	}
+
+	for i, answer := range answers {
+		other := answers[1-i]
+		b.sendMarkdown(answer.UserID, duelQuestionText(questionNumber, answer, other, b.displayName(other.UserID)), nil)
+	}
+}
+
+// duelQuestionText tells a participant how they and their opponent, named otherName, did on a question
+func duelQuestionText(questionNumber int, answer, other models.DuelAnswer, otherName string) string {
+	return fmt.Sprintf("⚔️ *Question %d:* you %s · %s %s", questionNumber,
+		formatDuelAnswer(answer), escapeMarkdown(otherName), formatDuelAnswer(other))
+}
+
+// formatDuelAnswer shows whether an answer was correct and how long it took
+func formatDuelAnswer(answer models.DuelAnswer) string {
+	mark := "❌"
+	if answer.IsCorrect {
+		mark = "✅"
+	}
+
+	if answer.TimeToAnswerMs == nil {
+		return mark
+	}
+	return fmt.Sprintf("%s %.1fs", mark, float64(*answer.TimeToAnswerMs)/1000)
+}
+
+// finishDuel announces the winner once both participants completed their sessions
+func (b *Bot) finishDuel(ctx context.Context, session *models.QuizSession) {
+	ctx = logging.With(ctx, "session_id", session.ID)
+
+	duelID := *session.DuelID
+
+	finished, err := b.duelService.FinishDuel(ctx, duelID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error finishing duel", "error", err)
+		return
+	}
+
+	duel, err := b.duelService.GetDuel(ctx, duelID)
+	if err != nil || duel == nil {
+		slog.ErrorContext(ctx, "Error retrieving duel", "duel_id", duelID, "error", err)
+		return
+	}
+
+	opponentID := duel.OtherParticipant(session.UserID)
+	if !finished {
+		if duel.Status == models.DuelStatusActive {
+			b.sendMessage(session.UserID, fmt.Sprintf("⏳ Waiting for %s to finish the duel...", b.displayName(opponentID)), nil)
+		}
+		return
+	}
+
+	results, err := b.duelService.GetDuelResults(ctx, duelID)
+	if err != nil || len(results) != 2 {
+		slog.ErrorContext(ctx, "Error retrieving duel results", "error", err)
+		return
+	}
+
+	for i, result := range results {
+		other := results[1-i]
+		b.sendMarkdown(result.UserID, duelResultText(result, other, len(duel.QuestionIDs), b.displayName(other.UserID)), nil)
+	}
+}
+
+// duelResultText announces the outcome of a duel of questionCount questions to a participant
+// who played against otherName
+func duelResultText(result, other models.DuelResult, questionCount int, otherName string) string {
+	// The name stays out of the bold text, where escapes aren't allowed
+	name := escapeMarkdown(otherName)
+
+	var text string
+	switch {
+	case result.Beats(other):
+		text = "🏆 *You won the duel!*"
+	case other.Beats(result):
+		text = fmt.Sprintf("😔 %s *won the duel.*", name)
+	default:
+		text = "🤝 *The duel is a draw!*"
+	}
+
+	text += fmt.Sprintf("\n\nYou: %d/%d correct in %.1fs\n%s: %d/%d correct in %.1fs",
+		result.Correct, questionCount, float64(result.TotalTimeMs)/1000,
+		name, other.Correct, questionCount, float64(other.TotalTimeMs)/1000)
+
+	if result.Correct == other.Correct && result.TotalTimeMs != other.TotalTimeMs {
+		text += "\n\n_Equal scores, so the faster player wins._"
+	}
+	return text
+}
+
+// displayName returns the display name of a known user, or a generic name
+func (b *Bot) displayName(userID int64) string {
+	if user, exists := b.userStore.GetUser(userID); exists {
+		return user.DisplayName()
+	}
+	return "your opponent"
+}
@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/amiosamu/interview-match-bot/internal/quizlint"
+)
+
+func TestDuelTextsEscapeNames(t *testing.T) {
+	timeMs := 1500
+	answer := models.DuelAnswer{UserID: 1, IsCorrect: true, TimeToAnswerMs: &timeMs}
+	other := models.DuelAnswer{UserID: 2}
+	winner := models.DuelResult{UserID: 1, Correct: 3, TotalTimeMs: 9000}
+	loser := models.DuelResult{UserID: 2, Correct: 2, TotalTimeMs: 8000}
+	tied := models.DuelResult{UserID: 2, Correct: 3, TotalTimeMs: 12000}
+
+	for _, name := range []string{"user_name", "*star*", "`code`", "[link"} {
+		texts := map[string]string{
+			"question": duelQuestionText(1, answer, other, name),
+			"won":      duelResultText(winner, loser, 3, name),
+			"lost":     duelResultText(loser, winner, 3, name),
+			"faster":   duelResultText(winner, tied, 3, name),
+		}
+
+		for kind, text := range texts {
+			if err := quizlint.CheckMarkdown(text); err != nil {
+				t.Errorf("%s text for %q isn't valid Markdown: %v\n%s", kind, name, err, text)
+			}
+			if !strings.Contains(text, escapeMarkdown(name)) {
+				t.Errorf("%s text doesn't contain the escaped name %q:\n%s", kind, escapeMarkdown(name), text)
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package bot
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/quizlint"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// sentMessage is a message the bot sent or edited, as seen by a user
+type sentMessage struct {
+	ChatID    int64
+	MessageID int
+	Text      string
+	Keyboard  *tgbotapi.InlineKeyboardMarkup
+	Edit      bool // an edit of an earlier message rather than a new one
+}
+
+// button returns the callback data of the button with the given text, if the message has one
+func (m sentMessage) button(text string) (string, bool) {
+	if m.Keyboard == nil {
+		return "", false
+	}
+
+	for _, row := range m.Keyboard.InlineKeyboard {
+		for _, button := range row {
+			if button.Text == text && button.CallbackData != nil {
+				return *button.CallbackData, true
+			}
+		}
+	}
+	return "", false
+}
+
+// fakeMessenger is a Messenger that records everything the bot sends instead of calling Telegram
+type fakeMessenger struct {
+	mutex         sync.Mutex
+	messages      []sentMessage
+	requests      []tgbotapi.Chattable
+	nextMessageID int
+	admins        map[int64]bool // users reported as chat admins
+	countRequests int            // GetChatMembersCount calls
+}
+
+// Send records a message, edit or poll and returns it with a new message ID
+func (f *fakeMessenger) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.nextMessageID++
+	sent := tgbotapi.Message{MessageID: f.nextMessageID}
+
+	switch config := c.(type) {
+	case tgbotapi.MessageConfig:
+		if err := checkParseMode(config.ParseMode, config.Text); err != nil {
+			return tgbotapi.Message{}, err
+		}
+		var keyboard *tgbotapi.InlineKeyboardMarkup
+		if markup, ok := config.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup); ok {
+			keyboard = &markup
+		}
+		f.record(sentMessage{ChatID: config.ChatID, MessageID: sent.MessageID, Text: config.Text, Keyboard: keyboard})
+		sent.Chat = &tgbotapi.Chat{ID: config.ChatID}
+		sent.Text = config.Text
+	case tgbotapi.EditMessageTextConfig:
+		if err := checkParseMode(config.ParseMode, config.Text); err != nil {
+			return tgbotapi.Message{}, err
+		}
+		f.record(sentMessage{ChatID: config.ChatID, MessageID: config.MessageID, Text: config.Text, Keyboard: config.ReplyMarkup, Edit: true})
+		sent.MessageID = config.MessageID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		f.record(sentMessage{ChatID: config.ChatID, MessageID: config.MessageID, Keyboard: config.ReplyMarkup, Edit: true})
+		sent.MessageID = config.MessageID
+	case tgbotapi.SendPollConfig:
+		var keyboard *tgbotapi.InlineKeyboardMarkup
+		if markup, ok := config.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup); ok {
+			keyboard = &markup
+		}
+		f.record(sentMessage{ChatID: config.ChatID, MessageID: sent.MessageID, Text: config.Question, Keyboard: keyboard})
+		sent.Chat = &tgbotapi.Chat{ID: config.ChatID}
+		sent.Poll = &tgbotapi.Poll{ID: strconv.Itoa(sent.MessageID), Question: config.Question}
+	default:
+		f.requests = append(f.requests, c)
+	}
+
+	return sent, nil
+}
+
+// checkParseMode fails like Telegram does for Markdown text it can't parse
+func checkParseMode(parseMode, text string) error {
+	if parseMode != tgbotapi.ModeMarkdown {
+		return nil
+	}
+	if err := quizlint.CheckMarkdown(text); err != nil {
+		return &tgbotapi.Error{Code: 400, Message: "Bad Request: can't parse entities: " + err.Error()}
+	}
+	return nil
+}
+
+// record adds a sent message. The caller must hold the mutex.
+func (f *fakeMessenger) record(message sentMessage) {
+	f.messages = append(f.messages, message)
+}
+
+// Request records a request, such as a callback answer, and reports success
+func (f *fakeMessenger) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.requests = append(f.requests, c)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+// GetChatMember reports every user as a member without a name, or as an administrator if listed in admins
+func (f *fakeMessenger) GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	status := "member"
+	if f.admins[config.UserID] {
+		status = "administrator"
+	}
+	return tgbotapi.ChatMember{User: &tgbotapi.User{ID: config.UserID}, Status: status}, nil
+}
+
+// GetChatMembersCount reports a chat of the bot and one user
+func (f *fakeMessenger) GetChatMembersCount(config tgbotapi.ChatMemberCountConfig) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.countRequests++
+	return 2, nil
+}
+
+// sent returns the messages sent so far
+func (f *fakeMessenger) sent() []sentMessage {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]sentMessage(nil), f.messages...)
+}
+
+// waitForMessage waits until the bot sent a message after the first skip ones whose text contains want.
+// It returns the message and the number of messages sent up to it.
+// Quiz follow-ups are sent in the background, so they may arrive a little later.
+func (f *fakeMessenger) waitForMessage(t *testing.T, skip int, want string) (sentMessage, int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		messages := f.sent()
+		for i := skip; i < len(messages); i++ {
+			if strings.Contains(messages[i].Text, want) {
+				return messages[i], i + 1
+			}
+		}
+
+		if time.Now().After(deadline) {
+			var texts []string
+			for _, message := range messages[min(skip, len(messages)):] {
+				texts = append(texts, strconv.Quote(message.Text))
+			}
+			t.Fatalf("no message containing %q, got:\n%s", want, strings.Join(texts, "\n"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// lastButton finds the newest message with a button with the given text and returns the message
+// and the button's callback data
+func (f *fakeMessenger) lastButton(t *testing.T, text string) (sentMessage, string) {
+	t.Helper()
+
+	messages := f.sent()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if data, ok := messages[i].button(text); ok {
+			return messages[i], data
+		}
+	}
+
+	t.Fatalf("no message with a %q button", text)
+	return sentMessage{}, ""
+}
@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleFlashcardCallback processes flashcard button actions.
+// Cards carry their language and question in the callback data, so flashcards need no session.
+func (b *Bot) handleFlashcardCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	user := b.saveUserInfo(ctx, query.From)
+	chatID := query.Message.Chat.ID
+	parts := strings.Split(query.Data, ":")
+
+	switch {
+	case strings.HasPrefix(query.Data, "flashcard:start:") && len(parts) == 3:
+		b.sendFlashcard(ctx, chatID, user.ID, parts[2])
+
+	case strings.HasPrefix(query.Data, "flashcard:reveal:") && len(parts) == 4:
+		questionID, err := strconv.Atoi(parts[3])
+		if err != nil {
+			b.sendMessage(chatID, "Invalid question. Please try again.", nil)
+			return
+		}
+		b.revealFlashcard(ctx, query.Message, parts[2], questionID)
+
+	case strings.HasPrefix(query.Data, "flashcard:rate:") && len(parts) == 5:
+		questionID, err := strconv.Atoi(parts[3])
+		if err != nil {
+			b.sendMessage(chatID, "Invalid question. Please try again.", nil)
+			return
+		}
+		b.rateFlashcard(ctx, query.Message, user.ID, parts[2], questionID, parts[4] == "knew")
+
+	case query.Data == "flashcard:stop":
+		b.send(tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+			InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+		}))
+		b.sendMessage(chatID, "🃏 Nice study session! Type /prepare to review more flashcards or take a quiz.", nil)
+
+	default:
+		b.sendMessage(chatID, "Invalid option. Please try again.", nil)
+	}
+}
+
+// sendFlashcard sends the question the user should review next in a language
+func (b *Bot) sendFlashcard(ctx context.Context, chatID int64, userID int64, language string) {
+	question, err := b.flashcardService.GetNextFlashcard(ctx, userID, language)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching flashcard", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't load a flashcard. Please try again later.", nil)
+		return
+	}
+
+	if question == nil {
+		if language == models.BookmarksLanguage {
+			b.sendMessage(chatID, "You have no bookmarked questions to review yet. Press 🔖 Bookmark on a quiz question or explanation to save it.", nil)
+			return
+		}
+		b.sendMessage(chatID, fmt.Sprintf("Sorry, no questions are available for %s yet. Please try another language.", formatLanguageName(language)), nil)
+		return
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("👀 Reveal", fmt.Sprintf("flashcard:reveal:%s:%d", language, question.ID)),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(chatID, formatFlashcard(language, question))
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
+
+// formatFlashcard formats the front of a flashcard: the question with its options
+func formatFlashcard(language string, question *models.QuizQuestion) string {
+	text := fmt.Sprintf("🃏 *Flashcard - %s*\n\n%s", formatLanguageName(language), question.QuestionText)
+
+	// Flashcards have no session, so the options keep their original order
+	if usesLetteredOptions(question) {
+		text += "\n\n" + formatLetteredOptions(&models.QuizSession{}, question)
+	} else if question.HasOptions() {
+		var options []string
+		for _, option := range question.AnswerOptions {
+			options = append(options, "• "+formatOptionText(option))
+		}
+		text += "\n\n" + strings.Join(options, "\n")
+	}
+
+	return text
+}
+
+// revealFlashcard turns a flashcard over, showing the correct answer and explanation,
+// and asks the user whether they knew it
+func (b *Bot) revealFlashcard(ctx context.Context, message *tgbotapi.Message, language string, questionID int) {
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
+		b.sendMessage(message.Chat.ID, "Sorry, I couldn't retrieve the question. Please try again later.", nil)
+		return
+	}
+
+	text := formatFlashcard(language, question)
+	text += fmt.Sprintf("\n\n*Answer:* %s\n\n*Explanation:*\n%s", formatCorrectAnswer(question), question.Explanation)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Knew it", fmt.Sprintf("flashcard:rate:%s:%d:knew", language, question.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Didn't", fmt.Sprintf("flashcard:rate:%s:%d:missed", language, question.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			bookmarkButton(question.ID),
+			tgbotapi.NewInlineKeyboardButtonData("🏁 Stop", "flashcard:stop"),
+		),
+	)
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, text, keyboard)
+	edit.ParseMode = "Markdown"
+	b.send(edit)
+}
+
+// rateFlashcard records whether the user knew a flashcard and sends the next one
+func (b *Bot) rateFlashcard(ctx context.Context, message *tgbotapi.Message, userID int64, language string, questionID int, knew bool) {
+	familiarity, err := b.flashcardService.RateFlashcard(ctx, userID, questionID, knew)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error rating flashcard", "error", err)
+		b.sendMessage(message.Chat.ID, "Sorry, I couldn't save your rating. Please try again later.", nil)
+		return
+	}
+
+	// Keep the card as it is, without the rating buttons
+	b.send(tgbotapi.NewEditMessageReplyMarkup(message.Chat.ID, message.MessageID, tgbotapi.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+	}))
+
+	rating := "❌ Didn't know it - this card will come back soon."
+	if knew {
+		rating = "✅ Knew it!"
+	}
+	b.sendMessage(message.Chat.ID, fmt.Sprintf("%s Familiarity: %s", rating, formatFamiliarity(familiarity)), nil)
+
+	b.sendFlashcard(ctx, message.Chat.ID, userID, language)
+}
+
+// formatFamiliarity shows a familiarity as filled and empty dots
+func formatFamiliarity(familiarity int) string {
+	return strings.Repeat("●", familiarity) + strings.Repeat("○", models.MaxFamiliarity-familiarity)
+}
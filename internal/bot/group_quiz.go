@@ -0,0 +1,427 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/logging"
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/amiosamu/interview-match-bot/internal/monitoring"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// leaderboardMedals decorate the first places of a group quiz leaderboard
+var leaderboardMedals = []string{"🥇", "🥈", "🥉"}
+
+// isGroupChat reports whether a chat is a group, where quizzes are shared by all members
+func isGroupChat(chat *tgbotapi.Chat) bool {
+	return chat != nil && (chat.IsGroup() || chat.IsSuperGroup())
+}
+
+// handleGroupPrepareCommand starts the quiz flow of a group chat
+func (b *Bot) handleGroupPrepareCommand(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+
+	session, err := b.quizService.GetActiveGroupQuizSession(ctx, chatID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving active group session", "error", err)
+		b.sendMessage(chatID, "Sorry, I encountered an error. Please try again later.", nil)
+		return
+	}
+
+	if session != nil {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("Continue Current Quiz", "group:continue"),
+				tgbotapi.NewInlineKeyboardButtonData("Start New Quiz", "group:new"),
+			),
+		)
+
+		b.sendMessage(chatID, fmt.Sprintf("This group has an unfinished %s quiz. Would you like to continue or start a new one?", formatLanguageName(session.Language)), keyboard)
+		return
+	}
+
+	b.sendGroupLanguageSelection(ctx, chatID)
+}
+
+// sendGroupLanguageSelection asks for the language of a group quiz
+func (b *Bot) sendGroupLanguageSelection(ctx context.Context, chatID int64) {
+	keyboard := b.quizLanguageKeyboard(ctx, func(language string) string {
+		return "group:lang:" + language
+	})
+
+	b.sendMessage(chatID, "Choose a programming language for the group quiz. Every member can answer each question once:", keyboard)
+}
+
+// handleGroupCallback processes button actions of group quizzes
+func (b *Bot) handleGroupCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	data := query.Data
+	chatID := query.Message.Chat.ID
+	user := b.saveUserInfo(ctx, query.From)
+
+	switch {
+	case data == "group:continue":
+		session, err := b.quizService.GetActiveGroupQuizSession(ctx, chatID)
+		if err != nil || session == nil {
+			b.sendMessage(chatID, "This group has no quiz in progress. Type /prepare to start one.", nil)
+			return
+		}
+		b.sendQuizQuestion(ctx, chatID, session.UserID, session)
+
+	case data == "group:new":
+		session, err := b.quizService.GetActiveGroupQuizSession(ctx, chatID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error retrieving active group session", "error", err)
+			b.sendMessage(chatID, "Sorry, I encountered an error. Please try again later.", nil)
+			return
+		}
+		if session != nil {
+			if !b.canAbandonGroupQuiz(ctx, chatID, user.ID, session) {
+				b.sendMessage(chatID, "Only the member who started the quiz or a chat admin can end it. Press Continue Current Quiz to keep playing.", nil)
+				return
+			}
+			b.abandonGroupQuiz(ctx, session)
+		}
+		b.sendGroupLanguageSelection(ctx, chatID)
+
+	case strings.HasPrefix(data, "group:lang:"):
+		b.startGroupQuiz(ctx, chatID, user, strings.TrimPrefix(data, "group:lang:"))
+
+	default:
+		b.sendMessage(chatID, "Invalid option. Please try again.", nil)
+	}
+}
+
+// startGroupQuiz begins a quiz shared by all members of a group chat.
+// Group questions are answered with buttons within the time limit, so only single-choice questions are used.
+func (b *Bot) startGroupQuiz(ctx context.Context, chatID int64, starter *models.User, language string) {
+	active, err := b.quizService.GetActiveGroupQuizSession(ctx, chatID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving active group session", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't start the quiz. Please try again later.", nil)
+		return
+	}
+
+	if active != nil {
+		b.sendMessage(chatID, "A quiz is already running in this group. Type /prepare to continue it or start a new one.", nil)
+		return
+	}
+
+	questions, err := b.quizService.GetQuestionsByType(ctx, language, models.QuestionTypeSingle, b.quiz.QuestionCount)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching questions", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't start the quiz. Please try again later.", nil)
+		return
+	}
+
+	if len(questions) == 0 {
+		b.sendMessage(chatID, fmt.Sprintf("Sorry, no questions are available for %s yet. Please try another language.", formatLanguageName(language)), nil)
+		return
+	}
+
+	// Every group question is open for a limited time, so group sessions are always timed
+	session, err := b.quizService.CreateQuizSession(ctx, starter.ID, language, questions, models.QuizOptions{
+		Timed:      true,
+		RenderMode: models.RenderModeKeyboard,
+		ChatID:     &chatID,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error creating group quiz session", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't start the quiz. Please try again later.", nil)
+		return
+	}
+	monitoring.QuizzesStarted.WithLabelValues(quizKind(session)).Inc()
+
+	b.sendMessage(chatID, fmt.Sprintf("📣 %s started a %s quiz with %d questions! Everyone can answer each question once before the countdown runs out. "+
+		"The most correct answers wins, and the faster player wins a tie.", starter.DisplayName(), formatLanguageName(language), len(questions)), nil)
+
+	// Give members a moment to read the rules
+	b.dispatcher.SubmitAfter(chatID, b.quiz.FirstQuestionDelay, func(ctx context.Context) {
+		b.sendQuizQuestion(ctx, chatID, starter.ID, session)
+	})
+}
+
+// processGroupAnswer records a member's answer to the current question of a group quiz.
+// Answers to earlier questions, closed questions and repeated answers are ignored.
+func (b *Bot) processGroupAnswer(ctx context.Context, query *tgbotapi.CallbackQuery, user *models.User, sessionID int, questionIndex int, questionID int, position int) {
+	chatID := query.Message.Chat.ID
+
+	session, err := b.quizService.GetActiveGroupQuizSession(ctx, chatID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving active group session", "error", err)
+		return
+	}
+
+	if session == nil || session.ID != sessionID || session.IsComplete() || session.CurrentQuestionIndex != questionIndex ||
+		session.QuestionIDs[questionIndex] != questionID {
+		return
+	}
+
+	// Only the message of the open question accepts answers
+	timer := b.runningQuestionTimer(session.ID)
+	if timer == nil || timer.messageID != query.Message.MessageID || timer.questionIndex != session.CurrentQuestionIndex {
+		return
+	}
+
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
+		return
+	}
+
+	if !question.HasOptions() || position < 0 || position >= len(question.AnswerOptions) {
+		return
+	}
+
+	answerIndex := session.OptionOrder(len(question.AnswerOptions))[position]
+	isCorrect := question.IsCorrectOption(answerIndex)
+
+	recorded, err := b.quizService.RecordGroupAnswer(ctx, user.ID, session.ID, question.ID, &answerIndex, question.AnswerOptions[answerIndex], isCorrect, session.TimeToAnswer(time.Now()))
+	if err != nil {
+		slog.ErrorContext(ctx, "Error recording group answer", "error", err)
+		return
+	}
+
+	if !recorded {
+		return
+	}
+
+	answers, err := b.quizService.GetGroupAnswers(ctx, session.ID, question.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving group answers", "error", err)
+		return
+	}
+
+	// Show who answered without revealing whether they were right
+	messageText, keyboard := renderQuizQuestion(session, question)
+	messageText += "\n\n✋ Answered: " + b.memberNames(chatID, answers)
+	b.updateTimerText(session.ID, messageText)
+
+	// Close the question early once every member answered
+	if b.allMembersAnswered(ctx, session, len(answers)) {
+		if timer := b.stopQuestionTimer(session.ID); timer != nil {
+			b.closeGroupQuestion(ctx, timer)
+		}
+		return
+	}
+
+	remaining := timer.limit
+	if elapsed := session.TimeToAnswer(time.Now()); elapsed != nil {
+		remaining -= *elapsed
+	}
+	if remaining > 0 {
+		edit := tgbotapi.NewEditMessageText(chatID, timer.messageID, formatCountdown(messageText, remaining))
+		edit.ParseMode = "Markdown"
+		edit.ReplyMarkup = &keyboard
+		b.send(edit)
+	}
+}
+
+// allMembersAnswered reports whether every member of a group chat answered the current question
+func (b *Bot) allMembersAnswered(ctx context.Context, session *models.QuizSession, answered int) bool {
+	count, ok := b.memberCount(ctx, session)
+	if !ok {
+		return false
+	}
+
+	// The bot itself is a member but never answers
+	return answered >= count-1
+}
+
+// memberCount returns the number of members of the chat of a group quiz.
+// It is looked up on the first answer and kept until the quiz ends, so members who join meanwhile aren't waited for.
+func (b *Bot) memberCount(ctx context.Context, session *models.QuizSession) (int, bool) {
+	b.memberCountsMutex.Lock()
+	count, exists := b.memberCounts[session.ID]
+	b.memberCountsMutex.Unlock()
+	if exists {
+		return count, true
+	}
+
+	count, err := b.api.GetChatMembersCount(tgbotapi.ChatMemberCountConfig{
+		ChatConfig: tgbotapi.ChatConfig{ChatID: *session.ChatID},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving member count", "chat_id", *session.ChatID, "error", err)
+		return 0, false
+	}
+
+	b.memberCountsMutex.Lock()
+	b.memberCounts[session.ID] = count
+	b.memberCountsMutex.Unlock()
+	return count, true
+}
+
+// forgetMemberCount drops the member count kept for a group quiz that ended
+func (b *Bot) forgetMemberCount(sessionID int) {
+	b.memberCountsMutex.Lock()
+	defer b.memberCountsMutex.Unlock()
+
+	delete(b.memberCounts, sessionID)
+}
+
+// closeGroupQuestion reveals the answer of a group question, credits the members who got it right and moves on
+func (b *Bot) closeGroupQuestion(ctx context.Context, timer *questionTimer) {
+	session, err := b.quizService.GetActiveGroupQuizSession(ctx, timer.chatID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving active group session", "error", err)
+		return
+	}
+
+	// The group may have started another quiz in the meantime
+	if session == nil || session.ID != timer.sessionID || session.CurrentQuestionIndex != timer.questionIndex {
+		return
+	}
+
+	questionID := session.QuestionIDs[session.CurrentQuestionIndex]
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
+		return
+	}
+
+	answers, err := b.quizService.GetGroupAnswers(ctx, session.ID, question.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving group answers", "error", err)
+		// Continue anyway - the group should still see the answer
+	}
+
+	// Remove the answer buttons from the closed question
+	edit := tgbotapi.NewEditMessageText(timer.chatID, timer.messageID, timer.text+"\n\n🔒 *Answers closed*")
+	edit.ParseMode = "Markdown"
+	b.send(edit)
+
+	var correct []models.GroupAnswer
+	for _, answer := range answers {
+		if answer.IsCorrect {
+			correct = append(correct, answer)
+		}
+	}
+
+	feedbackMessage := fmt.Sprintf("The correct answer is: %s\n\n", formatCorrectAnswer(question))
+	feedbackMessage += "*Explanation:*\n" + question.Explanation + "\n\n"
+	if len(correct) > 0 {
+		feedbackMessage += "✅ Correct: " + b.memberNames(timer.chatID, correct)
+	} else {
+		feedbackMessage += "❌ Nobody got this one right."
+	}
+
+	msg := tgbotapi.NewMessage(timer.chatID, feedbackMessage)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = bookmarkKeyboard(question.ID)
+	b.send(msg)
+
+	// The question closes for every member at once
+	questionIndex := session.CurrentQuestionIndex
+	if err := b.quizService.AdvanceQuizSession(ctx, session); err != nil {
+		slog.ErrorContext(ctx, "Error advancing group session", "error", err)
+		b.sendMessage(timer.chatID, "Sorry, I couldn't advance to the next question. Please try again later.", nil)
+		return
+	}
+
+	b.advanceQuiz(ctx, timer.chatID, session.UserID, session, questionIndex)
+}
+
+// completeGroupQuiz finishes a group quiz and posts the round leaderboard
+func (b *Bot) completeGroupQuiz(ctx context.Context, chatID int64, session *models.QuizSession) {
+	ctx = logging.With(ctx, "chat_id", chatID, "session_id", session.ID)
+
+	b.stopQuestionTimer(session.ID)
+	b.forgetMemberCount(session.ID)
+
+	err := b.quizService.CompleteQuizSession(ctx, session)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error completing group quiz session", "error", err)
+		// Continue anyway - the group should still see the leaderboard
+	} else {
+		monitoring.QuizzesCompleted.WithLabelValues(quizKind(session)).Inc()
+	}
+
+	scores, err := b.quizService.GetGroupLeaderboard(ctx, session.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving group leaderboard", "error", err)
+	}
+
+	resultsMessage := fmt.Sprintf("🏁 *The %s quiz is over!*\n\n", formatLanguageName(session.Language))
+
+	if len(scores) == 0 {
+		resultsMessage += "Nobody answered this round."
+	} else {
+		resultsMessage += "*Leaderboard:*\n"
+		for i, score := range scores {
+			place := fmt.Sprintf("%d.", i+1)
+			if i < len(leaderboardMedals) {
+				place = leaderboardMedals[i]
+			}
+
+			resultsMessage += fmt.Sprintf("%s %s - %d/%d correct, %.1fs\n", place, escapeMarkdown(b.memberName(chatID, score.UserID)),
+				score.Correct, session.CurrentQuestionIndex, float64(score.TotalTimeMs)/1000)
+		}
+	}
+
+	resultsMessage += "\nType /prepare to play another round."
+
+	msg := tgbotapi.NewMessage(chatID, resultsMessage)
+	msg.ParseMode = "Markdown"
+	b.send(msg)
+
+	for _, score := range scores {
+		b.unlockAchievements(ctx, chatID, score.UserID, models.EventQuizCompleted)
+	}
+}
+
+// canAbandonGroupQuiz reports whether a member may end the quiz of a group chat:
+// only the member who started it and the chat admins may
+func (b *Bot) canAbandonGroupQuiz(ctx context.Context, chatID int64, userID int64, session *models.QuizSession) bool {
+	if userID == session.UserID {
+		return true
+	}
+
+	member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving chat member", "chat_id", chatID, "user_id", userID, "error", err)
+		return false
+	}
+
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// abandonGroupQuiz ends the quiz of a group chat before its last question
+func (b *Bot) abandonGroupQuiz(ctx context.Context, session *models.QuizSession) {
+	b.stopQuestionTimer(session.ID)
+	b.forgetMemberCount(session.ID)
+	if err := b.quizService.CompleteQuizSession(ctx, session); err != nil {
+		slog.ErrorContext(ctx, "Error abandoning group quiz session", "error", err)
+	}
+}
+
+// memberNames lists the names of the members who gave the answers, escaped for Markdown
+func (b *Bot) memberNames(chatID int64, answers []models.GroupAnswer) string {
+	var names []string
+	for _, answer := range answers {
+		names = append(names, escapeMarkdown(b.memberName(chatID, answer.UserID)))
+	}
+	return strings.Join(names, ", ")
+}
+
+// memberName returns the display name of a group member.
+// Members the bot doesn't know yet are looked up in the chat.
+func (b *Bot) memberName(chatID int64, userID int64) string {
+	if user, exists := b.userStore.GetUser(userID); exists {
+		return user.DisplayName()
+	}
+
+	member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID},
+	})
+	if err != nil || member.User == nil {
+		return fmt.Sprintf("User %d", userID)
+	}
+
+	return (&models.User{Username: member.User.UserName, FirstName: member.User.FirstName, LastName: member.User.LastName}).DisplayName()
+}
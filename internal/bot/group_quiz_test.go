@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// testGroupID is the chat the group quiz tests play in
+const testGroupID = -100
+
+// groupCallbackUpdate presses a button of a message in the test group
+func (b *testBot) groupCallbackUpdate(message sentMessage, data string) tgbotapi.Update {
+	update := b.callbackUpdate(message, data)
+	update.CallbackQuery.Message.Chat = &tgbotapi.Chat{ID: testGroupID, Type: "group"}
+	return update
+}
+
+// pressInGroup presses the latest button with the given text in the test group
+func (b *testBot) pressInGroup(t *testing.T, text string) {
+	t.Helper()
+
+	message, data := b.messenger.lastButton(t, text)
+	b.dispatchUpdate(b.groupCallbackUpdate(message, data))
+}
+
+// startTestGroupQuiz starts a group quiz in the test group for the given member without sending its questions
+func startTestGroupQuiz(t *testing.T, b *testBot, language string, starterID int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	questions, err := b.quizService.GetQuestionsByLanguage(ctx, language, 10)
+	if err != nil {
+		t.Fatalf("getting questions: %v", err)
+	}
+	chatID := int64(testGroupID)
+	if _, err := b.quizService.CreateQuizSession(ctx, starterID, language, questions, models.QuizOptions{Timed: true, ChatID: &chatID}); err != nil {
+		t.Fatalf("creating group session: %v", err)
+	}
+}
+
+func TestAbandonGroupQuiz(t *testing.T) {
+	language := "testing"
+	startNew := sentMessage{ChatID: testGroupID}
+
+	t.Run("by the starter", func(t *testing.T) {
+		b := newTestBot(t, testQuizRepository(t, language))
+		startTestGroupQuiz(t, b, language, b.user.ID)
+
+		b.dispatchUpdate(b.groupCallbackUpdate(startNew, "group:new"))
+		b.messenger.waitForMessage(t, 0, "Choose a programming language for the group quiz")
+	})
+
+	t.Run("by another member", func(t *testing.T) {
+		b := newTestBot(t, testQuizRepository(t, language))
+		startTestGroupQuiz(t, b, language, b.user.ID+1)
+
+		b.dispatchUpdate(b.groupCallbackUpdate(startNew, "group:new"))
+		b.messenger.waitForMessage(t, 0, "Only the member who started the quiz or a chat admin can end it.")
+
+		session, err := b.quizService.GetActiveGroupQuizSession(context.Background(), testGroupID)
+		if err != nil || session == nil {
+			t.Errorf("GetActiveGroupQuizSession = %v, %v, want the running quiz", session, err)
+		}
+	})
+
+	t.Run("by a chat admin", func(t *testing.T) {
+		b := newTestBot(t, testQuizRepository(t, language))
+		b.messenger.admins = map[int64]bool{b.user.ID: true}
+		startTestGroupQuiz(t, b, language, b.user.ID+1)
+
+		b.dispatchUpdate(b.groupCallbackUpdate(startNew, "group:new"))
+		b.messenger.waitForMessage(t, 0, "Choose a programming language for the group quiz")
+	})
+}
+
+func TestGroupMemberCountIsLookedUpOncePerQuiz(t *testing.T) {
+	language := "testing"
+	b := newTestBot(t, testQuizRepository(t, language))
+
+	b.dispatchUpdate(b.groupCallbackUpdate(sentMessage{ChatID: testGroupID}, "group:lang:"+language))
+	_, seen := b.messenger.waitForMessage(t, 0, "Question 1 of 2")
+	b.pressInGroup(t, "Yes")
+	_, seen = b.messenger.waitForMessage(t, seen, "Question 2 of 2")
+	b.pressInGroup(t, "Yes")
+	b.messenger.waitForMessage(t, seen, "quiz is over!")
+
+	b.messenger.mutex.Lock()
+	defer b.messenger.mutex.Unlock()
+	if b.messenger.countRequests != 1 {
+		t.Errorf("got %d member count requests, want 1", b.messenger.countRequests)
+	}
+}
@@ -1,11 +1,21 @@
 package bot
 
 import (
+	"context"
+	"strings"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // handleStartCommand processes the /start command
-func (b *Bot) handleStartCommand(message *tgbotapi.Message) {
+func (b *Bot) handleStartCommand(ctx context.Context, message *tgbotapi.Message) {
+	// Duel invite links open the bot with a duel payload
+	if payload := message.CommandArguments(); strings.HasPrefix(payload, duelStartPrefix) {
+		user := b.saveUserInfo(ctx, message.From)
+		b.handleDuelInviteLink(ctx, message.Chat.ID, user, payload)
+		return
+	}
+
 	welcomeText := "Welcome to Interview Match Bot! Please select your field of interest:"
 	b.sendMessage(message.Chat.ID, welcomeText, CreateCategoriesKeyboard())
 }
@@ -19,6 +29,13 @@ This bot helps you find partners for interview practice.
 *Commands:*
 /start - Start the bot and select your category
 /help - Show this help message
+/prepare - Practice with interview quizzes (in a group chat, starts a quiz for all members)
+/duel - Challenge someone to a quiz duel
+/leaderboard - See the weekly and all-time quiz rankings
+/daily - Answer today's daily challenge question
+/stats - See your quiz results and daily streak
+/achievements - See the milestones you unlocked
+/bookmarks - Browse and quiz the questions you bookmarked
 
 *How to use:*
 1. Select your field of interest (e.g., Backend, Frontend)
@@ -29,5 +46,5 @@ If you have any issues, please try restarting the bot with /start.`
 
 	msg := tgbotapi.NewMessage(message.Chat.ID, helpText)
 	msg.ParseMode = "Markdown"
-	b.api.Send(msg)
+	b.send(msg)
 }
@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// matchKeyboard offers what a user can do with a matched partner: challenge them to a quiz duel,
+// and confirm the mock interview once they had it
+func matchKeyboard(partnerID int64) tgbotapi.InlineKeyboardMarkup {
+	keyboard := duelChallengeKeyboard(partnerID)
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ We Had Our Mock Interview", fmt.Sprintf("interview:done:%d", partnerID)),
+	))
+	return keyboard
+}
+
+// handleInterviewCallback processes mock interview button actions.
+// The callback data is interview:done:<partner ID>.
+func (b *Bot) handleInterviewCallback(ctx context.Context, query *tgbotapi.CallbackQuery, user *models.User) {
+	chatID := query.Message.Chat.ID
+
+	partnerID, err := strconv.ParseInt(strings.TrimPrefix(query.Data, "interview:done:"), 10, 64)
+	if err != nil || partnerID == user.ID {
+		b.sendMessage(chatID, "Invalid option. Please try again.", nil)
+		return
+	}
+
+	partner, exists := b.userStore.GetUser(partnerID)
+	if !exists {
+		b.sendMessage(chatID, "Invalid option. Please try again.", nil)
+		return
+	}
+
+	b.sendMarkdown(chatID, fmt.Sprintf("🎉 Well done on your mock interview with %s! Keep practicing with /prepare.",
+		escapeMarkdown(partner.DisplayName())), nil)
+	b.unlockAchievements(ctx, chatID, user.ID, models.EventInterviewCompleted)
+}
@@ -0,0 +1,29 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/amiosamu/interview-match-bot/internal/service"
+)
+
+func TestMockInterviewConfirmation(t *testing.T) {
+	b := newTestBot(t, service.NewMemoryQuizRepository())
+	b.userStore.SaveUser(&models.User{ID: b.user.ID + 1_000_000, Username: "partner_one", Field: "Go", Level: "Junior"})
+
+	b.run(t, []step{
+		{send: "/start", want: "Please select your field of interest"},
+		{press: "Go", want: "Now select your experience level"},
+		{press: "Junior", want: "I found a match! User @partner_one",
+			wantButtons: []string{"⚔️ Challenge to a quiz duel", "✅ We Had Our Mock Interview"}},
+	})
+
+	// The partner is notified too, so the button is pressed on the message in the user's chat
+	for _, message := range b.messenger.sent() {
+		if data, ok := message.button("✅ We Had Our Mock Interview"); ok && message.ChatID == b.user.ID {
+			b.dispatchUpdate(b.callbackUpdate(message, data))
+			break
+		}
+	}
+	b.messenger.waitForMessage(t, b.seen, `Well done on your mock interview with @partner\_one`)
+}
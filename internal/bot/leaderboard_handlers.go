@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Leaderboard settings
+const (
+	leaderboardSize         = 10    // number of ranked users shown
+	leaderboardMinQuestions = 10    // questions a user must answer in the period to be ranked
+	leaderboardAllLanguages = "all" // callback value for the leaderboard across all languages
+)
+
+// handleLeaderboardCommand asks which language's leaderboard to show
+func (b *Bot) handleLeaderboardCommand(ctx context.Context, message *tgbotapi.Message) {
+	keyboard := b.quizLanguageKeyboard(ctx, func(language string) string {
+		return fmt.Sprintf("leaderboard:show:%s:%s", language, models.LeaderboardWeekly)
+	})
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🌍 All Languages", fmt.Sprintf("leaderboard:show:%s:%s", leaderboardAllLanguages, models.LeaderboardWeekly)),
+	))
+
+	b.sendMessage(message.Chat.ID, "🏆 Which leaderboard would you like to see?", keyboard)
+}
+
+// handleLeaderboardCallback processes leaderboard button actions
+func (b *Bot) handleLeaderboardCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	user := b.saveUserInfo(ctx, query.From)
+	parts := strings.Split(query.Data, ":")
+
+	switch {
+	case strings.HasPrefix(query.Data, "leaderboard:show:") && len(parts) == 4:
+		b.showLeaderboard(ctx, query.Message, user, parts[2], parts[3])
+
+	case strings.HasPrefix(query.Data, "leaderboard:privacy:") && len(parts) == 5:
+		if !b.requireDatabase(query.Message.Chat.ID) {
+			return
+		}
+		optOut := parts[2] == "hide"
+		if err := b.profileService.SetLeaderboardOptOut(ctx, user.ID, optOut); err != nil {
+			slog.ErrorContext(ctx, "Error updating leaderboard setting", "error", err)
+			b.sendMessage(query.Message.Chat.ID, "Sorry, I couldn't update your setting. Please try again later.", nil)
+			return
+		}
+		b.showLeaderboard(ctx, query.Message, user, parts[3], parts[4])
+
+	default:
+		b.sendMessage(query.Message.Chat.ID, "Invalid option. Please try again.", nil)
+	}
+}
+
+// showLeaderboard replaces a message with the leaderboard of a language and period
+func (b *Bot) showLeaderboard(ctx context.Context, message *tgbotapi.Message, user *models.User, language string, period string) {
+	var since *time.Time
+	title := "all time"
+	if period == models.LeaderboardWeekly {
+		weekAgo := time.Now().AddDate(0, 0, -7)
+		since = &weekAgo
+		title = "this week"
+	}
+
+	languageFilter := language
+	languageName := "Overall"
+	if language == leaderboardAllLanguages {
+		languageFilter = ""
+	} else {
+		languageName = formatLanguageName(language)
+	}
+
+	entries, err := b.quizService.GetLeaderboard(ctx, languageFilter, since, leaderboardMinQuestions, leaderboardSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving leaderboard", "error", err)
+		b.sendMessage(message.Chat.ID, "Sorry, I couldn't load the leaderboard. Please try again later.", nil)
+		return
+	}
+
+	text := fmt.Sprintf("🏆 *%s leaderboard - %s*\n\n", languageName, title)
+
+	if len(entries) == 0 {
+		text += "Nobody has answered enough questions to be ranked yet. Type /prepare to be the first!\n"
+	}
+
+	for i, entry := range entries {
+		place := fmt.Sprintf("%d.", i+1)
+		if i < len(leaderboardMedals) {
+			place = leaderboardMedals[i]
+		}
+
+		text += fmt.Sprintf("%s %s - %.0f%% (%d/%d)\n", place, escapeMarkdown(b.leaderboardName(entry)),
+			entry.Accuracy(), entry.Correct, entry.Questions)
+	}
+
+	text += fmt.Sprintf("\n_Ranked by accuracy, then by correct answers, among players with at least %d answers. "+
+		"Answers given after a hint score half._", leaderboardMinQuestions)
+
+	optOut := false
+	if b.db != nil {
+		var err error
+		optOut, err = b.profileService.IsLeaderboardOptOut(ctx, user.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Error retrieving leaderboard setting", "error", err)
+		}
+	}
+
+	edit := tgbotapi.NewEditMessageTextAndMarkup(message.Chat.ID, message.MessageID, text, leaderboardKeyboard(language, period, optOut))
+	edit.ParseMode = "Markdown"
+	b.send(edit)
+}
+
+// leaderboardKeyboard lets the user switch the period of a leaderboard and hide themselves from leaderboards
+func leaderboardKeyboard(language string, period string, optOut bool) tgbotapi.InlineKeyboardMarkup {
+	periodButton := func(text string, value string) tgbotapi.InlineKeyboardButton {
+		if value == period {
+			text = "• " + text + " •"
+		}
+		return tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("leaderboard:show:%s:%s", language, value))
+	}
+
+	privacyText, privacyValue := "🙈 Hide Me from Leaderboards", "hide"
+	if optOut {
+		privacyText, privacyValue = "👀 Show Me on Leaderboards", "show"
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			periodButton("This Week", models.LeaderboardWeekly),
+			periodButton("All Time", models.LeaderboardAllTime),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(privacyText, fmt.Sprintf("leaderboard:privacy:%s:%s:%s", privacyValue, language, period)),
+		),
+	)
+}
+
+// leaderboardName returns the name a user is listed with on a leaderboard
+func (b *Bot) leaderboardName(entry models.LeaderboardEntry) string {
+	if name := entry.User.DisplayName(); name != "" {
+		return name
+	}
+
+	if user, exists := b.userStore.GetUser(entry.User.ID); exists {
+		return user.DisplayName()
+	}
+
+	return "Anonymous player"
+}
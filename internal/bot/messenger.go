@@ -0,0 +1,71 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/amiosamu/interview-match-bot/internal/monitoring"
+	"github.com/amiosamu/interview-match-bot/internal/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Messenger is the part of the Telegram Bot API the handlers use to talk to users.
+// *tgbotapi.BotAPI implements it; tests use a fake that records what was sent.
+type Messenger interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error)
+	GetChatMembersCount(config tgbotapi.ChatMemberCountConfig) (int, error)
+}
+
+// send queues a message, edit or poll in the outbox and returns at once.
+// The error is logged if sending fails.
+func (b *Bot) send(c tgbotapi.Chattable) {
+	b.outbox.Post(c, func(_ tgbotapi.Message, err error) {
+		if err != nil {
+			logRequestError(c, err)
+		}
+	})
+}
+
+// sendThen queues a message or poll like send. Once it was sent, then is run with it
+// in order with the updates of chatID, e.g. to start the countdown of a question.
+func (b *Bot) sendThen(chatID int64, c tgbotapi.Chattable, then func(ctx context.Context, sent tgbotapi.Message)) {
+	b.outbox.Post(c, func(sent tgbotapi.Message, err error) {
+		if err != nil {
+			logRequestError(c, err)
+			return
+		}
+		b.dispatcher.Submit(chatID, func(ctx context.Context) {
+			then(ctx, sent)
+		})
+	})
+}
+
+// sendAndWait sends a message, edit or poll and waits until it was sent, logging the error if it fails.
+// Update handlers use send instead, so they don't wait for the rate limits.
+func (b *Bot) sendAndWait(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	message, err := b.outbox.Send(c)
+	if err != nil {
+		logRequestError(c, err)
+	}
+	return message, err
+}
+
+// request queues a request, such as a callback answer, like send
+func (b *Bot) request(c tgbotapi.Chattable) {
+	b.outbox.PostRequest(c, func(_ *tgbotapi.APIResponse, err error) {
+		if err != nil {
+			logRequestError(c, err)
+		}
+	})
+}
+
+// logRequestError logs a failed request with its name and, if it has one, its chat
+func logRequestError(c tgbotapi.Chattable, err error) {
+	if chatID, ok := sender.ChatOf(c); ok {
+		slog.Error("Error making Telegram request", "request", monitoring.RequestName(c), "chat_id", chatID, "error", err)
+		return
+	}
+	slog.Error("Error making Telegram request", "request", monitoring.RequestName(c), "error", err)
+}
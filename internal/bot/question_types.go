@@ -0,0 +1,316 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// selectedMark prefixes the options a user has toggled on in a multi-select question
+const selectedMark = "✅ "
+
+// letteredOptionsThreshold is the longest option that is still put on a button as is.
+// Questions with a longer or multi-line option list all options as A/B/C/D in the
+// message body instead, with short lettered buttons.
+const letteredOptionsThreshold = 30
+
+// lettersPerRow is how many lettered buttons share a keyboard row
+const lettersPerRow = 4
+
+// renderQuizQuestion builds the message text and keyboard for the current question of a session.
+// Questions answered by typing only get the quiz action buttons.
+func renderQuizQuestion(session *models.QuizSession, question *models.QuizQuestion) (string, tgbotapi.InlineKeyboardMarkup) {
+	questionNumber := session.CurrentQuestionIndex + 1
+	totalQuestions := len(session.QuestionIDs)
+	messageText := fmt.Sprintf("*Question %d of %d*\n\n%s", questionNumber, totalQuestions, question.QuestionText)
+
+	lettered := usesLetteredOptions(question)
+	if lettered {
+		messageText += "\n\n" + formatLetteredOptions(session, question)
+	}
+
+	// A used hint stays visible when the question is shown again
+	eliminated := map[int]bool{}
+	if session.HintUsed {
+		var hintText string
+		hintText, eliminated = formatHint(session, question)
+		messageText += "\n\n" + hintText
+	}
+
+	switch question.Type {
+	case models.QuestionTypeMulti:
+		messageText += "\n\n_Select all that apply, then press Submit._"
+		return messageText, multiSelectKeyboard(session, question, nil)
+	case models.QuestionTypeText:
+		messageText += "\n\n✍️ _Type your answer as a message._"
+		return messageText, tgbotapi.NewInlineKeyboardMarkup(quizActionRows(session, question)...)
+	case models.QuestionTypeOutput:
+		messageText += "\n\n✍️ _Reply with the exact output of the program._"
+		return messageText, tgbotapi.NewInlineKeyboardMarkup(quizActionRows(session, question)...)
+	}
+
+	// Create answer buttons in the session's shuffled order.
+	// The callback carries the displayed position, not the option index.
+	var rows [][]tgbotapi.InlineKeyboardButton
+	shown := 0
+	for position, optionIndex := range session.OptionOrder(len(question.AnswerOptions)) {
+		if eliminated[optionIndex] {
+			continue
+		}
+
+		callbackData := fmt.Sprintf("quiz:answer:%d:%d:%d:%d", session.ID, session.CurrentQuestionIndex, question.ID, position)
+		button := tgbotapi.NewInlineKeyboardButtonData(question.AnswerOptions[optionIndex], callbackData)
+
+		if !lettered {
+			rows = append(rows, []tgbotapi.InlineKeyboardButton{button})
+			continue
+		}
+
+		button.Text = optionLetter(position)
+		if shown%lettersPerRow == 0 {
+			rows = append(rows, nil)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], button)
+		shown++
+	}
+
+	rows = append(rows, quizActionRows(session, question)...)
+	return messageText, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// usesLetteredOptions reports whether a question's options are too long or
+// contain code spanning several lines, so they don't fit on buttons
+func usesLetteredOptions(question *models.QuizQuestion) bool {
+	if !question.HasOptions() {
+		return false
+	}
+
+	for _, option := range question.AnswerOptions {
+		if len([]rune(option)) > letteredOptionsThreshold || strings.Contains(option, "\n") {
+			return true
+		}
+	}
+	return false
+}
+
+// optionLetter returns the label of the option at a displayed position: A, B, C...
+func optionLetter(position int) string {
+	return string(rune('A' + position))
+}
+
+// formatLetteredOptions lists the options of a question in the session's order as A/B/C/D
+func formatLetteredOptions(session *models.QuizSession, question *models.QuizQuestion) string {
+	var lines []string
+	for position, optionIndex := range session.OptionOrder(len(question.AnswerOptions)) {
+		lines = append(lines, fmt.Sprintf("*%s.* %s", optionLetter(position), formatOptionText(question.AnswerOptions[optionIndex])))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatOptionText renders an option for a Markdown message.
+// Multi-line options become code blocks and code-like options inline code,
+// so they are shown in monospace; anything else is escaped.
+func formatOptionText(option string) string {
+	switch {
+	case strings.Contains(option, "\n") && !strings.Contains(option, "```"):
+		return "\n```\n" + option + "\n```"
+	case looksLikeCode(option) && !strings.Contains(option, "`"):
+		return "`" + option + "`"
+	default:
+		return escapeMarkdown(option)
+	}
+}
+
+// looksLikeCode guesses whether an option is a code snippet rather than prose
+func looksLikeCode(option string) bool {
+	return strings.ContainsAny(option, "{}();=[]<>:*&") || strings.HasPrefix(option, "func ")
+}
+
+// escapeMarkdown escapes the characters that start entities in legacy Markdown
+func escapeMarkdown(text string) string {
+	replacer := strings.NewReplacer("_", "\\_", "*", "\\*", "`", "\\`", "[", "\\[")
+	return replacer.Replace(text)
+}
+
+// multiSelectKeyboard creates toggle buttons for every option plus a Submit button.
+// selected holds the displayed positions the user has toggled on.
+func multiSelectKeyboard(session *models.QuizSession, question *models.QuizQuestion, selected map[int]bool) tgbotapi.InlineKeyboardMarkup {
+	lettered := usesLetteredOptions(question)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for position, optionIndex := range session.OptionOrder(len(question.AnswerOptions)) {
+		text := question.AnswerOptions[optionIndex]
+		if lettered {
+			text = optionLetter(position)
+		}
+		if selected[position] {
+			text = selectedMark + text
+		}
+
+		callbackData := fmt.Sprintf("quiz:toggle:%d:%d:%d:%d", session.ID, session.CurrentQuestionIndex, question.ID, position)
+		button := tgbotapi.NewInlineKeyboardButtonData(text, callbackData)
+
+		if !lettered {
+			rows = append(rows, []tgbotapi.InlineKeyboardButton{button})
+			continue
+		}
+
+		if position%lettersPerRow == 0 {
+			rows = append(rows, nil)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], button)
+	}
+
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Submit", fmt.Sprintf("quiz:submit:%d:%d:%d", session.ID, session.CurrentQuestionIndex, question.ID)),
+	))
+	rows = append(rows, quizActionRows(session, question)...)
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// selectedPositions reads the toggled options back from a multi-select keyboard.
+// Keeping the selection in the message itself means it survives bot restarts.
+func selectedPositions(markup *tgbotapi.InlineKeyboardMarkup) map[int]bool {
+	selected := make(map[int]bool)
+	if markup == nil {
+		return selected
+	}
+
+	for _, row := range markup.InlineKeyboard {
+		for _, button := range row {
+			if button.CallbackData == nil || !strings.HasPrefix(button.Text, selectedMark) {
+				continue
+			}
+
+			values, ok := parseCallbackNumbers(*button.CallbackData, "quiz:toggle:", 4)
+			if ok {
+				selected[values[3]] = true
+			}
+		}
+	}
+
+	return selected
+}
+
+// toggleQuizOption flips an option of a multi-select question on or off
+func (b *Bot) toggleQuizOption(ctx context.Context, query *tgbotapi.CallbackQuery, userID int64, sessionID int, questionIndex int, questionID int, position int) {
+	chatID := query.Message.Chat.ID
+	session, question := b.currentQuizQuestion(ctx, chatID, userID, sessionID, questionIndex, questionID)
+	if session == nil {
+		return
+	}
+
+	if question.Type != models.QuestionTypeMulti || position < 0 || position >= len(question.AnswerOptions) {
+		b.sendMessage(chatID, "Invalid answer selection. Please try again.", nil)
+		return
+	}
+
+	selected := selectedPositions(query.Message.ReplyMarkup)
+	selected[position] = !selected[position]
+
+	keyboard := multiSelectKeyboard(session, question, selected)
+	b.updateTimerKeyboard(session.ID, keyboard)
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, keyboard)
+	b.send(edit)
+}
+
+// processMultiSelectAnswer grades the options selected in a multi-select question
+func (b *Bot) processMultiSelectAnswer(ctx context.Context, query *tgbotapi.CallbackQuery, userID int64, sessionID int, questionIndex int, questionID int) {
+	chatID := query.Message.Chat.ID
+	session, question := b.currentQuizQuestion(ctx, chatID, userID, sessionID, questionIndex, questionID)
+	if session == nil {
+		return
+	}
+
+	if question.Type != models.QuestionTypeMulti {
+		b.sendMessage(chatID, "Invalid answer selection. Please try again.", nil)
+		return
+	}
+
+	selected := selectedPositions(query.Message.ReplyMarkup)
+	if len(selected) == 0 {
+		b.sendMessage(chatID, "Please select at least one option before submitting.", nil)
+		return
+	}
+
+	// Map the displayed positions back to option indexes
+	order := session.OptionOrder(len(question.AnswerOptions))
+	var optionIndexes []int
+	var options []string
+	for position := range order {
+		if selected[position] {
+			optionIndexes = append(optionIndexes, order[position])
+			options = append(options, question.AnswerOptions[order[position]])
+		}
+	}
+
+	isCorrect := question.IsCorrectSelection(optionIndexes)
+	b.submitQuizAnswer(ctx, chatID, userID, session, question, nil, strings.Join(options, ", "), isCorrect)
+}
+
+// handleQuizTextAnswer treats a plain message as the answer to a free-text or output question
+// while one is on screen. Between questions, messages aren't taken as answers.
+// It returns false if the user isn't currently expected to type an answer.
+func (b *Bot) handleQuizTextAnswer(ctx context.Context, message *tgbotapi.Message) bool {
+	session, err := b.quizService.GetActiveQuizSession(ctx, message.From.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving active session", "error", err)
+		return false
+	}
+
+	// The current question is only waiting for an answer once it was sent
+	if session == nil || session.IsComplete() || session.QuestionSentAt == nil {
+		return false
+	}
+
+	questionID := session.QuestionIDs[session.CurrentQuestionIndex]
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
+		return false
+	}
+
+	if question.HasOptions() {
+		return false
+	}
+
+	isCorrect := question.IsCorrectText(message.Text)
+	b.submitQuizAnswer(ctx, message.Chat.ID, message.From.ID, session, question, nil, message.Text, isCorrect)
+	return true
+}
+
+// formatCorrectAnswer renders the correct answer for feedback messages.
+// Program output is shown as a code block so whitespace is preserved,
+// and long options are formatted the same way as in the question.
+func formatCorrectAnswer(question *models.QuizQuestion) string {
+	if question.Type == models.QuestionTypeOutput {
+		return "\n```\n" + question.CorrectAnswerText() + "\n```"
+	}
+
+	if usesLetteredOptions(question) {
+		var options []string
+		if question.Type == models.QuestionTypeMulti {
+			for _, i := range question.CorrectOptionIndexes {
+				if i >= 0 && i < len(question.AnswerOptions) {
+					options = append(options, question.AnswerOptions[i])
+				}
+			}
+		} else {
+			options = append(options, question.CorrectOption())
+		}
+
+		var formatted []string
+		for _, option := range options {
+			formatted = append(formatted, formatOptionText(option))
+		}
+		return "\n" + strings.Join(formatted, "\n")
+	}
+
+	return "*" + question.CorrectAnswerText() + "*"
+}
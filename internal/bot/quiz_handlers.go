@@ -1,24 +1,33 @@
 package bot
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/amiosamu/interview-match-bot/internal/logging"
 	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/amiosamu/interview-match-bot/internal/monitoring"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // handlePrepareCommand initiates the interview preparation quiz flow
-func (b *Bot) handlePrepareCommand(message *tgbotapi.Message) {
-	user := b.saveUserInfo(message.From)
+func (b *Bot) handlePrepareCommand(ctx context.Context, message *tgbotapi.Message) {
+	// Group chats share a single quiz between all members
+	if isGroupChat(message.Chat) {
+		b.handleGroupPrepareCommand(ctx, message)
+		return
+	}
+
+	user := b.saveUserInfo(ctx, message.From)
 
 	// Check if the user already has an active quiz session
-	session, err := b.quizService.GetActiveQuizSession(user.ID)
+	session, err := b.quizService.GetActiveQuizSession(ctx, user.ID)
 	if err != nil {
-		log.Printf("Error retrieving active quiz session: %v", err)
+		slog.ErrorContext(ctx, "Error retrieving active quiz session", "error", err)
 		b.sendMessage(message.Chat.ID, "Sorry, I encountered an error. Please try again later.", nil)
 		return
 	}
@@ -37,13 +46,23 @@ func (b *Bot) handlePrepareCommand(message *tgbotapi.Message) {
 	}
 
 	// No active quiz, show language selection
-	b.sendQuizLanguageSelection(message.Chat.ID)
+	b.sendQuizLanguageSelection(ctx, message.Chat.ID)
 }
 
 // sendQuizLanguageSelection shows available programming languages for quizzes
-func (b *Bot) sendQuizLanguageSelection(chatID int64) {
+func (b *Bot) sendQuizLanguageSelection(ctx context.Context, chatID int64) {
+	keyboard := b.quizLanguageKeyboard(ctx, func(language string) string {
+		return "quiz:lang:" + language
+	})
+
+	b.sendMessage(chatID, "Choose a programming language for your interview preparation quiz:", keyboard)
+}
+
+// quizLanguageKeyboard creates a keyboard with the available quiz languages.
+// callbackData returns the callback data of the button for a language.
+func (b *Bot) quizLanguageKeyboard(ctx context.Context, callbackData func(language string) string) tgbotapi.InlineKeyboardMarkup {
 	// Get available languages or use a predefined list if the database query fails
-	languages, err := b.quizService.GetQuizLanguages()
+	languages, err := b.quizService.GetQuizLanguages(ctx)
 	if err != nil || len(languages) == 0 {
 		// Fallback to hardcoded languages
 		languages = []string{"golang", "python", "javascript", "java", "csharp", "ruby"}
@@ -58,20 +77,18 @@ func (b *Bot) sendQuizLanguageSelection(chatID int64) {
 
 		// Add the first language in this row
 		buttonText := formatLanguageName(languages[i])
-		row = append(row, tgbotapi.NewInlineKeyboardButtonData(buttonText, "quiz:lang:"+languages[i]))
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(buttonText, callbackData(languages[i])))
 
 		// Add the second language if there is one
 		if i+1 < len(languages) {
 			buttonText = formatLanguageName(languages[i+1])
-			row = append(row, tgbotapi.NewInlineKeyboardButtonData(buttonText, "quiz:lang:"+languages[i+1]))
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(buttonText, callbackData(languages[i+1])))
 		}
 
 		rows = append(rows, row)
 	}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
-
-	b.sendMessage(chatID, "Choose a programming language for your interview preparation quiz:", keyboard)
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // formatLanguageName converts language code to a nice display name
@@ -95,212 +112,425 @@ func formatLanguageName(lang string) string {
 }
 
 // handleQuizCallback processes quiz-related button actions
-func (b *Bot) handleQuizCallback(query *tgbotapi.CallbackQuery) {
+func (b *Bot) handleQuizCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
 	// Always acknowledge the callback query to stop the loading indicator
 	callback := tgbotapi.NewCallback(query.ID, "")
-	b.api.Request(callback)
+	b.request(callback)
 
 	data := query.Data
-	user := b.saveUserInfo(query.From)
+	user := b.saveUserInfo(ctx, query.From)
 
 	// Handle different quiz callbacks
 	if data == "quiz:continue" {
-		b.continueQuiz(query.Message.Chat.ID, user.ID)
+		b.continueQuiz(ctx, query.Message.Chat.ID, user.ID)
 		return
 	}
 
 	if data == "quiz:new" {
 		// End any active session and show language selection
-		session, err := b.quizService.GetActiveQuizSession(user.ID)
-		if err == nil && session != nil {
-			b.quizService.CompleteQuizSession(session.ID)
-		}
+		b.abandonActiveQuiz(ctx, user.ID)
 
-		b.sendQuizLanguageSelection(query.Message.Chat.ID)
+		b.sendQuizLanguageSelection(ctx, query.Message.Chat.ID)
 		return
 	}
 
 	if strings.HasPrefix(data, "quiz:lang:") {
-		// Extract the language
+		// Extract the language and ask for the quiz mode
 		language := strings.TrimPrefix(data, "quiz:lang:")
-		b.startNewQuiz(query.Message.Chat.ID, user.ID, language)
+		b.sendQuizModeSelection(query.Message.Chat.ID, language)
 		return
 	}
 
-	if strings.HasPrefix(data, "quiz:answer:") {
-		// Extract session ID and answer index
+	if strings.HasPrefix(data, "quiz:mode:") {
+		// Extract the language and mode
 		parts := strings.Split(data, ":")
 		if len(parts) != 4 {
 			b.sendMessage(query.Message.Chat.ID, "Invalid option. Please try again.", nil)
 			return
 		}
 
-		sessionID, err := strconv.Atoi(parts[2])
-		if err != nil {
-			b.sendMessage(query.Message.Chat.ID, "Invalid session. Please try again.", nil)
+		options := models.QuizOptions{RenderMode: models.RenderModeKeyboard}
+		switch parts[3] {
+		case "timed":
+			options.Timed = true
+		case "poll":
+			options.RenderMode = models.RenderModePoll
+		}
+
+		b.startNewQuiz(ctx, query.Message.Chat.ID, user.ID, parts[2], options)
+		return
+	}
+
+	// Buttons of a question carry the session ID, the index of the question in the session and
+	// the question ID, so presses on the buttons of questions that were already answered can be
+	// told apart. The ID is needed as well because a skip moves another question to the same index.
+	if strings.HasPrefix(data, "quiz:answer:") {
+		// The displayed position of the chosen option follows
+		values, ok := parseCallbackNumbers(data, "quiz:answer:", 4)
+		if !ok {
+			b.sendMessage(query.Message.Chat.ID, "Invalid option. Please try again.", nil)
 			return
 		}
 
-		answerIndex, err := strconv.Atoi(parts[3])
-		if err != nil {
-			b.sendMessage(query.Message.Chat.ID, "Invalid answer. Please try again.", nil)
+		if isGroupChat(query.Message.Chat) {
+			b.processGroupAnswer(ctx, query, user, values[0], values[1], values[2], values[3])
+			return
+		}
+
+		b.processQuizAnswer(ctx, query.Message.Chat.ID, user.ID, values[0], values[1], values[2], values[3])
+		return
+	}
+
+	if strings.HasPrefix(data, "quiz:toggle:") {
+		// The option position of a multi-select question follows
+		values, ok := parseCallbackNumbers(data, "quiz:toggle:", 4)
+		if !ok {
+			b.sendMessage(query.Message.Chat.ID, "Invalid option. Please try again.", nil)
+			return
+		}
+
+		b.toggleQuizOption(ctx, query, user.ID, values[0], values[1], values[2], values[3])
+		return
+	}
+
+	if strings.HasPrefix(data, "quiz:hint:") {
+		values, ok := parseCallbackNumbers(data, "quiz:hint:", 3)
+		if !ok {
+			b.sendMessage(query.Message.Chat.ID, "Invalid option. Please try again.", nil)
+			return
+		}
+
+		b.useQuizHint(ctx, query, user.ID, values[0], values[1], values[2])
+		return
+	}
+
+	if strings.HasPrefix(data, "quiz:skip:") {
+		values, ok := parseCallbackNumbers(data, "quiz:skip:", 3)
+		if !ok {
+			b.sendMessage(query.Message.Chat.ID, "Invalid option. Please try again.", nil)
+			return
+		}
+
+		b.skipQuizQuestion(ctx, query, user.ID, values[0], values[1], values[2])
+		return
+	}
+
+	if strings.HasPrefix(data, "quiz:submit:") {
+		values, ok := parseCallbackNumbers(data, "quiz:submit:", 3)
+		if !ok {
+			b.sendMessage(query.Message.Chat.ID, "Invalid option. Please try again.", nil)
 			return
 		}
 
-		b.processQuizAnswer(query.Message.Chat.ID, user.ID, sessionID, answerIndex)
+		b.processMultiSelectAnswer(ctx, query, user.ID, values[0], values[1], values[2])
 		return
 	}
 }
 
+// parseCallbackNumbers returns the colon-separated numbers following prefix in callback data.
+// It returns false unless there are exactly count of them.
+func parseCallbackNumbers(data string, prefix string, count int) ([]int, bool) {
+	parts := strings.Split(strings.TrimPrefix(data, prefix), ":")
+	if len(parts) != count {
+		return nil, false
+	}
+
+	values := make([]int, count)
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		values[i] = value
+	}
+
+	return values, true
+}
+
+// sendQuizModeSelection asks whether the quiz should be timed or use quiz polls
+func (b *Bot) sendQuizModeSelection(chatID int64, language string) {
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Practice", "quiz:mode:"+language+":practice"),
+			tgbotapi.NewInlineKeyboardButtonData("⏱ Timed", "quiz:mode:"+language+":timed"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📊 Quiz Polls", "quiz:mode:"+language+":poll"),
+			tgbotapi.NewInlineKeyboardButtonData("🃏 Flashcards", "flashcard:start:"+language),
+		),
+	)
+
+	text := fmt.Sprintf("How would you like to take the %s quiz?\n\n"+
+		"*Practice* - take as long as you need for each question.\n"+
+		"*Timed* - answer each question before the countdown runs out, just like in a real interview.\n"+
+		"*Quiz Polls* - practice with Telegram's native quiz polls where possible.\n"+
+		"*Flashcards* - reveal the answers yourself and rate whether you knew them, without a score.", formatLanguageName(language))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
+
+// abandonActiveQuiz ends the user's active quiz session, if any
+func (b *Bot) abandonActiveQuiz(ctx context.Context, userID int64) {
+	session, err := b.quizService.GetActiveQuizSession(ctx, userID)
+	if err != nil || session == nil {
+		return
+	}
+
+	b.stopQuestionTimer(session.ID)
+	b.quizService.CompleteQuizSession(ctx, session)
+
+	// An abandoned duel ends with the answers given so far
+	if session.DuelID != nil {
+		b.finishDuel(ctx, session)
+	}
+}
+
 // startNewQuiz begins a new quiz session for a user
-func (b *Bot) startNewQuiz(chatID int64, userID int64, language string) {
-	// Get random questions for this language (10 questions per quiz)
-	questions, err := b.quizService.GetQuestionsByLanguage(language, 10)
+func (b *Bot) startNewQuiz(ctx context.Context, chatID int64, userID int64, language string, options models.QuizOptions) {
+	// Get random questions for this language (b.quiz.QuestionCount per quiz),
+	// or from the user's bookmarks for a bookmark quiz
+	var questions []*models.QuizQuestion
+	var err error
+	if language == models.BookmarksLanguage {
+		if !b.requireDatabase(chatID) {
+			return
+		}
+		questions, err = b.bookmarkService.GetBookmarkedQuestions(ctx, userID, b.quiz.QuestionCount)
+	} else {
+		questions, err = b.quizService.GetQuestionsByLanguage(ctx, language, b.quiz.QuestionCount)
+	}
 	if err != nil {
-		log.Printf("Error fetching questions: %v", err)
+		slog.ErrorContext(ctx, "Error fetching questions", "error", err)
 		b.sendMessage(chatID, "Sorry, I couldn't start a quiz for this language. Please try again later.", nil)
 		return
 	}
 
 	if len(questions) == 0 {
+		if language == models.BookmarksLanguage {
+			b.sendMessage(chatID, "You have no bookmarked questions to quiz yet. Press 🔖 Bookmark on a quiz question or explanation to save it.", nil)
+			return
+		}
 		b.sendMessage(chatID, fmt.Sprintf("Sorry, no questions are available for %s yet. Please try another language.", formatLanguageName(language)), nil)
 		return
 	}
 
-	// Extract question IDs
-	var questionIDs []int
-	for _, q := range questions {
-		questionIDs = append(questionIDs, q.ID)
-	}
-
 	// Create a new quiz session
-	session, err := b.quizService.CreateQuizSession(userID, language, questionIDs)
+	session, err := b.quizService.CreateQuizSession(ctx, userID, language, questions, options)
 	if err != nil {
-		log.Printf("Error creating quiz session: %v", err)
+		slog.ErrorContext(ctx, "Error creating quiz session", "error", err)
 		b.sendMessage(chatID, "Sorry, I couldn't start the quiz. Please try again later.", nil)
 		return
 	}
+	monitoring.QuizzesStarted.WithLabelValues(quizKind(session)).Inc()
 
 	// Send introduction message
-	b.sendMessage(chatID, fmt.Sprintf("Starting a new %s quiz with %d questions. Let's begin!", formatLanguageName(language), len(questionIDs)), nil)
-
-	// Wait a moment before sending the first question
-	time.Sleep(1 * time.Second)
+	intro := fmt.Sprintf("Starting a new %s quiz with %d questions. Let's begin!", formatLanguageName(language), len(session.QuestionIDs))
+	if options.Timed {
+		intro = fmt.Sprintf("Starting a new timed %s quiz with %d questions. Unanswered questions count as wrong when the time runs out. Let's begin!", formatLanguageName(language), len(session.QuestionIDs))
+	}
+	b.sendMessage(chatID, intro, nil)
 
-	// Send the first question
-	b.sendQuizQuestion(chatID, userID, session)
+	// Send the first question after a moment, without holding up other updates
+	b.dispatcher.SubmitAfter(chatID, b.quiz.FirstQuestionDelay, func(ctx context.Context) {
+		b.sendQuizQuestion(ctx, chatID, userID, session)
+	})
 }
 
 // continueQuiz resumes an existing quiz session
-func (b *Bot) continueQuiz(chatID int64, userID int64) {
-	session, err := b.quizService.GetActiveQuizSession(userID)
+func (b *Bot) continueQuiz(ctx context.Context, chatID int64, userID int64) {
+	session, err := b.quizService.GetActiveQuizSession(ctx, userID)
 	if err != nil {
-		log.Printf("Error retrieving active session: %v", err)
+		slog.ErrorContext(ctx, "Error retrieving active session", "error", err)
 		b.sendMessage(chatID, "Sorry, I couldn't retrieve your quiz. Please try starting a new one.", nil)
 		return
 	}
 
 	if session == nil {
 		b.sendMessage(chatID, "You don't have an active quiz. Please start a new one.", nil)
-		b.sendQuizLanguageSelection(chatID)
+		b.sendQuizLanguageSelection(ctx, chatID)
 		return
 	}
 
 	// Send the current question
-	b.sendQuizQuestion(chatID, userID, session)
+	b.sendQuizQuestion(ctx, chatID, userID, session)
 }
 
 // sendQuizQuestion sends the current question to the user
-func (b *Bot) sendQuizQuestion(chatID int64, userID int64, session *models.QuizSession) {
+func (b *Bot) sendQuizQuestion(ctx context.Context, chatID int64, userID int64, session *models.QuizSession) {
+	ctx = logging.With(ctx, "user_id", userID, "chat_id", chatID, "session_id", session.ID)
+
 	// Check if the quiz is complete
 	if session.IsComplete() {
-		b.completeQuiz(chatID, userID, session)
+		b.completeQuiz(ctx, chatID, userID, session)
 		return
 	}
 
 	// Get the current question
 	questionID := session.QuestionIDs[session.CurrentQuestionIndex]
-	question, err := b.quizService.GetQuestionByID(questionID)
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
 	if err != nil {
-		log.Printf("Error fetching question %d: %v", questionID, err)
+		slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
 		b.sendMessage(chatID, "Sorry, I couldn't retrieve the question. Please try again later.", nil)
 		return
 	}
 
-	// Create the question message
-	questionNumber := session.CurrentQuestionIndex + 1
-	totalQuestions := len(session.QuestionIDs)
-	messageText := fmt.Sprintf("*Question %d of %d*\n\n%s", questionNumber, totalQuestions, question.QuestionText)
-
-	// Create answer buttons
-	var rows [][]tgbotapi.InlineKeyboardButton
-	for i, option := range question.AnswerOptions {
-		callbackData := fmt.Sprintf("quiz:answer:%d:%d", session.ID, i)
-		rows = append(rows, []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData(option, callbackData),
-		})
+	// Questions that fit are sent as native quiz polls in poll mode
+	if session.RenderMode == models.RenderModePoll && canRenderAsPoll(question) {
+		b.sendQuizPoll(ctx, chatID, session, question)
+		return
 	}
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	// Create the question message with type-specific answer controls
+	messageText, keyboard := renderQuizQuestion(session, question)
+
+	// Timed questions show the countdown right away
+	limit := b.questionTimeLimit(question.Difficulty)
+	text := messageText
+	if session.Timed {
+		text = formatCountdown(messageText, limit)
+	}
 
 	// Send the message with the keyboard
-	msg := tgbotapi.NewMessage(chatID, messageText)
+	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "Markdown"
-	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+	// The time to answer and the countdown start once the question was sent
+	b.sendThen(chatID, msg, func(ctx context.Context, sent tgbotapi.Message) {
+		// Remember when the question was shown to measure the time to answer
+		if err := b.quizService.MarkQuestionSent(ctx, session, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "Error marking question as sent", "error", err)
+		}
+
+		if session.Timed {
+			b.startQuestionTimer(chatID, userID, session, sent.MessageID, messageText, keyboard, limit)
+		}
+	})
 }
 
-// processQuizAnswer handles a user's answer to a quiz question
-func (b *Bot) processQuizAnswer(chatID int64, userID int64, sessionID int, answerIndex int) {
+// processQuizAnswer handles a user's answer to a single-choice quiz question.
+// position is the displayed position of the chosen option in the session's shuffled order.
+func (b *Bot) processQuizAnswer(ctx context.Context, chatID int64, userID int64, sessionID int, questionIndex int, questionID int, position int) {
+	session, question := b.currentQuizQuestion(ctx, chatID, userID, sessionID, questionIndex, questionID)
+	if session == nil {
+		return
+	}
+
+	// Check if the answer position is valid
+	if !question.HasOptions() || position < 0 || position >= len(question.AnswerOptions) {
+		b.sendMessage(chatID, "Invalid answer selection. Please try again.", nil)
+		return
+	}
+
+	// Map the displayed position back to the option index
+	answerIndex := session.OptionOrder(len(question.AnswerOptions))[position]
+
+	// Get the selected answer
+	selectedAnswer := question.AnswerOptions[answerIndex]
+
+	// Check if the answer is correct
+	isCorrect := question.IsCorrectOption(answerIndex)
+
+	b.submitQuizAnswer(ctx, chatID, userID, session, question, &answerIndex, selectedAnswer, isCorrect)
+}
+
+// currentQuizQuestion fetches the user's active session and its current question.
+// It tells the user what went wrong and returns nils if the session isn't the expected one
+// or has moved on from questionID, the question at questionIndex.
+func (b *Bot) currentQuizQuestion(ctx context.Context, chatID int64, userID int64, sessionID int, questionIndex int, questionID int) (*models.QuizSession, *models.QuizQuestion) {
 	// Get the active session
-	session, err := b.quizService.GetActiveQuizSession(userID)
+	session, err := b.quizService.GetActiveQuizSession(ctx, userID)
 	if err != nil {
-		log.Printf("Error retrieving active session: %v", err)
+		slog.ErrorContext(ctx, "Error retrieving active session", "error", err)
 		b.sendMessage(chatID, "Sorry, I couldn't process your answer. Please try again later.", nil)
-		return
+		return nil, nil
 	}
 
-	if session == nil || session.ID != sessionID {
+	if session == nil || session.ID != sessionID || session.IsComplete() {
 		b.sendMessage(chatID, "This quiz is no longer active. Please start a new one.", nil)
-		return
+		return nil, nil
+	}
+
+	// Buttons of earlier questions stay on their messages, e.g. when an answer button is pressed twice
+	if session.CurrentQuestionIndex != questionIndex {
+		b.sendMessage(chatID, "This question was already answered.", nil)
+		return nil, nil
+	}
+
+	// A skip moves the next question to the index of the skipped one
+	if session.QuestionIDs[session.CurrentQuestionIndex] != questionID {
+		b.sendMessage(chatID, "This question was skipped. It will come back at the end of the quiz.", nil)
+		return nil, nil
 	}
 
 	// Get the current question
-	questionID := session.QuestionIDs[session.CurrentQuestionIndex]
-	question, err := b.quizService.GetQuestionByID(questionID)
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
 	if err != nil {
-		log.Printf("Error fetching question %d: %v", questionID, err)
+		slog.ErrorContext(ctx, "Error fetching question", "session_id", session.ID, "question_id", questionID, "error", err)
 		b.sendMessage(chatID, "Sorry, I couldn't retrieve the question. Please try again later.", nil)
+		return nil, nil
+	}
+
+	return session, question
+}
+
+// submitQuizAnswer records a graded answer to the current question, sends feedback and moves on.
+// answerIndex is the chosen option of a single-choice question and nil for other types.
+func (b *Bot) submitQuizAnswer(ctx context.Context, chatID int64, userID int64, session *models.QuizSession, question *models.QuizQuestion, answerIndex *int, answerGiven string, isCorrect bool) {
+	ctx = logging.With(ctx, "user_id", userID, "chat_id", chatID, "session_id", session.ID)
+
+	// In timed mode the answer only counts if the countdown is still running
+	var timer *questionTimer
+	if session.Timed {
+		timer = b.runningQuestionTimer(session.ID)
+		if timer == nil {
+			b.sendMessage(chatID, "⏰ Time is up for this question. Type /prepare to continue your quiz.", nil)
+			return
+		}
+	}
+
+	// Record the answer, update the score and move on to the next question at once.
+	// Of two answers to the same question, e.g. from a double tap, only the first is counted.
+	// The countdown keeps running until then, so the question still expires if the answer isn't recorded;
+	// an expiry that comes in meanwhile finds the question answered.
+	questionIndex := session.CurrentQuestionIndex
+	submitted, err := b.quizService.SubmitAnswer(ctx, session, questionIndex, answerIndex, answerGiven, isCorrect, session.TimeToAnswer(time.Now()))
+	if err != nil {
+		slog.ErrorContext(ctx, "Error submitting answer", "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't record your answer. Please try again later.", nil)
 		return
 	}
 
-	// Check if the answer index is valid
-	if answerIndex < 0 || answerIndex >= len(question.AnswerOptions) {
-		b.sendMessage(chatID, "Invalid answer selection. Please try again.", nil)
+	if !submitted {
+		b.sendMessage(chatID, "This question was already answered.", nil)
 		return
 	}
 
-	// Get the selected answer
-	selectedAnswer := question.AnswerOptions[answerIndex]
+	if timer != nil {
+		b.stopQuestionTimer(session.ID)
 
-	// Check if the answer is correct
-	isCorrect := selectedAnswer == question.CorrectAnswer
+		// Drop the countdown and the answer buttons from the question
+		edit := tgbotapi.NewEditMessageText(timer.chatID, timer.messageID, timer.text)
+		edit.ParseMode = "Markdown"
+		b.send(edit)
+	}
 
-	// Record the answer
-	err = b.quizService.RecordAnswer(userID, session.ID, questionID, selectedAnswer, isCorrect)
-	if err != nil {
-		log.Printf("Error recording answer: %v", err)
-		// Continue anyway - this isn't critical
+	// Quiz polls show the result and explanation themselves
+	if pollShowsFeedback(session, question) {
+		b.advanceQuiz(ctx, chatID, userID, session, questionIndex)
+		return
 	}
 
 	// Prepare feedback message
 	var feedbackMessage string
 	if isCorrect {
 		feedbackMessage = "✅ *Correct!*\n\n"
-		// Update the session's correct answers count locally as well
-		session.CorrectAnswers++
 	} else {
-		feedbackMessage = fmt.Sprintf("❌ *Incorrect*\n\nThe correct answer is: *%s*\n\n", question.CorrectAnswer)
+		feedbackMessage = fmt.Sprintf("❌ *Incorrect*\n\nThe correct answer is: %s\n\n", formatCorrectAnswer(question))
 	}
 
 	// Add explanation
@@ -309,43 +539,59 @@ func (b *Bot) processQuizAnswer(chatID int64, userID int64, sessionID int, answe
 	// Send feedback
 	msg := tgbotapi.NewMessage(chatID, feedbackMessage)
 	msg.ParseMode = "Markdown"
-	b.api.Send(msg)
+	msg.ReplyMarkup = bookmarkKeyboard(question.ID)
+	b.send(msg)
 
-	// Advance to the next question
-	err = b.quizService.AdvanceQuizSession(session.ID)
-	if err != nil {
-		log.Printf("Error advancing session: %v", err)
-		b.sendMessage(chatID, "Sorry, I couldn't advance to the next question. Please try again later.", nil)
-		return
-	}
+	b.advanceQuiz(ctx, chatID, userID, session, questionIndex)
+}
 
-	// Update session locally
-	session.CurrentQuestionIndex++
+// advanceQuiz follows up on an answered question: it sends the next question of the session,
+// which already moved on, or completes the quiz once the user had time to read the feedback.
+// questionIndex is the index of the answered question.
+func (b *Bot) advanceQuiz(ctx context.Context, chatID int64, userID int64, session *models.QuizSession, questionIndex int) {
+	// Duel participants see how both did once both answered
+	if session.DuelID != nil {
+		b.announceDuelQuestion(ctx, session, questionIndex)
+	}
 
-	// Wait before showing the next question
-	time.Sleep(3 * time.Second)
+	b.dispatcher.SubmitAfter(chatID, b.quiz.NextQuestionDelay, func(ctx context.Context) {
+		if session.CurrentQuestionIndex >= len(session.QuestionIDs) {
+			b.completeQuiz(ctx, chatID, userID, session)
+		} else {
+			b.sendQuizQuestion(ctx, chatID, userID, session)
+		}
+	})
+}
 
-	// Send the next question or complete the quiz
-	if session.CurrentQuestionIndex >= len(session.QuestionIDs) {
-		b.completeQuiz(chatID, userID, session)
-	} else {
-		b.sendQuizQuestion(chatID, userID, session)
+// quizKind labels a session in the quiz metrics: solo, duel or group
+func quizKind(session *models.QuizSession) string {
+	switch {
+	case session.IsGroup():
+		return "group"
+	case session.DuelID != nil:
+		return "duel"
 	}
+	return "solo"
 }
 
 // completeQuiz finishes a quiz session and shows results
-func (b *Bot) completeQuiz(chatID int64, userID int64, session *models.QuizSession) {
-	// Mark the session as complete in the database
-	if session.CompletedAt == nil {
-		err := b.quizService.CompleteQuizSession(session.ID)
-		if err != nil {
-			log.Printf("Error completing quiz session: %v", err)
-			// Continue anyway - the user should still see their results
-		}
+func (b *Bot) completeQuiz(ctx context.Context, chatID int64, userID int64, session *models.QuizSession) {
+	ctx = logging.With(ctx, "user_id", userID, "chat_id", chatID, "session_id", session.ID)
 
-		// Set completed time locally
-		now := time.Now()
-		session.CompletedAt = &now
+	if session.IsGroup() {
+		b.completeGroupQuiz(ctx, chatID, session)
+		return
+	}
+
+	b.stopQuestionTimer(session.ID)
+
+	// Mark the session as complete
+	err := b.quizService.CompleteQuizSession(ctx, session)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error completing quiz session", "error", err)
+		// Continue anyway - the user should still see their results
+	} else {
+		monitoring.QuizzesCompleted.WithLabelValues(quizKind(session)).Inc()
 	}
 
 	// Calculate score percentage
@@ -368,6 +614,11 @@ func (b *Bot) completeQuiz(chatID int64, userID int64, session *models.QuizSessi
 	resultsMessage += fmt.Sprintf("*Your score: %.1f%%* (%d correct out of %d questions)\n\n",
 		score, session.CorrectAnswers, session.CurrentQuestionIndex)
 
+	if session.HintedAnswers > 0 || session.Skips > 0 {
+		resultsMessage += fmt.Sprintf("💡 Hints used for correct answers: %d (they count half)\n⏭ Questions skipped: %d\n\n",
+			session.HintedAnswers, session.Skips)
+	}
+
 	// Add recommendations based on score
 	if score < 60 {
 		resultsMessage += "It looks like you might benefit from studying this topic more. Would you like to try another quiz or return to the main menu?"
@@ -389,5 +640,11 @@ func (b *Bot) completeQuiz(chatID int64, userID int64, session *models.QuizSessi
 	msg := tgbotapi.NewMessage(chatID, resultsMessage)
 	msg.ParseMode = "Markdown"
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.send(msg)
+
+	b.unlockAchievements(ctx, chatID, userID, models.EventQuizCompleted)
+
+	if session.DuelID != nil {
+		b.finishDuel(ctx, session)
+	}
 }
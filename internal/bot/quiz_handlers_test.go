@@ -0,0 +1,282 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/amiosamu/interview-match-bot/internal/service"
+)
+
+// testQuizRepository returns a repository with two single-choice questions in a language of their own.
+// "Yes" is the correct answer to both, so the tests needn't know in which order they come.
+func testQuizRepository(t *testing.T, language string) service.QuizRepository {
+	t.Helper()
+
+	repo := service.NewMemoryQuizRepository()
+	for i := 1; i <= 2; i++ {
+		question := &models.QuizQuestion{
+			Language:           language,
+			Category:           "Testing",
+			Difficulty:         "beginner",
+			Type:               models.QuestionTypeSingle,
+			QuestionText:       fmt.Sprintf("Is this test question %d?", i),
+			AnswerOptions:      []string{"Yes", "No", "Maybe"},
+			CorrectAnswer:      "Yes",
+			CorrectOptionIndex: 0,
+			Explanation:        "It is a test question.",
+		}
+		if _, err := repo.UpsertQuestion(context.Background(), question); err != nil {
+			t.Fatalf("creating test question: %v", err)
+		}
+	}
+
+	return repo
+}
+
+func TestQuizConversations(t *testing.T) {
+	language := "testing"
+	languageName := formatLanguageName(language)
+
+	// startQuiz starts a practice quiz from /prepare up to the first question
+	startQuiz := []step{
+		{send: "/prepare", want: "Choose a programming language", wantButtons: []string{languageName}},
+		{press: languageName, want: "How would you like to take the " + languageName + " quiz?",
+			wantButtons: []string{"Practice", "⏱ Timed", "📊 Quiz Polls", "🃏 Flashcards"}},
+		{press: "Practice", want: "Starting a new " + languageName + " quiz with 2 questions"},
+		{want: "Question 1 of 2", wantButtons: []string{"Yes", "No", "Maybe", "🔖 Bookmark"}},
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "start a quiz",
+			steps: startQuiz,
+		},
+		{
+			name: "answer every question correctly",
+			steps: append(startQuiz[:len(startQuiz):len(startQuiz)],
+				step{press: "Yes", want: "Correct!", wantButtons: []string{"🔖 Bookmark"}},
+				step{want: "Question 2 of 2"},
+				step{press: "Yes", want: "Correct!"},
+				step{want: "Your score: 100.0%", wantButtons: []string{"Take Another Quiz", "Main Menu"}},
+			),
+		},
+		{
+			name: "answer incorrectly",
+			steps: append(startQuiz[:len(startQuiz):len(startQuiz)],
+				step{press: "No", want: "Incorrect"},
+				step{want: "Question 2 of 2"},
+				step{press: "Yes", want: "Correct!"},
+				step{want: "Your score: 50.0%"},
+			),
+		},
+		{
+			name: "answer incorrectly with the correct answer shown",
+			steps: append(startQuiz[:len(startQuiz):len(startQuiz)],
+				step{press: "Maybe", want: "The correct answer is: *Yes*"},
+				step{want: "Question 2 of 2"},
+				step{press: "No", want: "Incorrect"},
+				step{want: "Your score: 0.0%"},
+			),
+		},
+		{
+			name: "press an answer button twice",
+			steps: append(startQuiz[:len(startQuiz):len(startQuiz)],
+				step{press: "No", twice: true, want: "Incorrect"},
+				step{want: "This question was already answered."},
+				step{want: "Question 2 of 2"},
+				step{press: "Yes", want: "Correct!"},
+				step{want: "Your score: 50.0%"},
+			),
+		},
+		{
+			name: "continue an unfinished quiz",
+			steps: append(startQuiz[:len(startQuiz):len(startQuiz)],
+				step{send: "/prepare", want: "You have an unfinished " + language + " quiz",
+					wantButtons: []string{"Continue Current Quiz", "Start New Quiz"}},
+				step{press: "Continue Current Quiz", want: "Question 1 of 2"},
+				step{press: "Yes", want: "Correct!"},
+				step{want: "Question 2 of 2"},
+			),
+		},
+		{
+			name: "start a new quiz instead of the unfinished one",
+			steps: append(startQuiz[:len(startQuiz):len(startQuiz)],
+				step{send: "/prepare", want: "You have an unfinished " + language + " quiz"},
+				step{press: "Start New Quiz", want: "Choose a programming language"},
+				step{press: languageName, want: "How would you like to take the " + languageName + " quiz?"},
+				step{press: "Practice", want: "Starting a new " + languageName + " quiz"},
+				step{want: "Question 1 of 2"},
+			),
+		},
+		{
+			name: "take another quiz",
+			steps: append(startQuiz[:len(startQuiz):len(startQuiz)],
+				step{press: "Yes", want: "Correct!"},
+				step{want: "Question 2 of 2"},
+				step{press: "Yes", want: "Correct!"},
+				step{want: "Your score"},
+				step{press: "Take Another Quiz", want: "Choose a programming language"},
+			),
+		},
+		{
+			name: "press Skip twice",
+			steps: append(startQuiz[:len(startQuiz):len(startQuiz)],
+				step{press: "⏭ Skip", twice: true, want: "Skipped - this question will come back at the end of the quiz."},
+				step{want: "Question 1 of 2"},
+				step{want: "This question was skipped."},
+				step{press: "Yes", want: "Correct!"},
+				step{want: "Question 2 of 2"},
+			),
+		},
+		{
+			name: "bookmark a poll question",
+			steps: []step{
+				{send: "/prepare", want: "Choose a programming language"},
+				{press: languageName, want: "How would you like to take the " + languageName + " quiz?"},
+				{press: "📊 Quiz Polls", want: "Starting a new " + languageName + " quiz with 2 questions"},
+				{want: "Question 1 of 2", wantButtons: []string{"🔖 Bookmark"}},
+			},
+		},
+		{
+			name: "answer a finished quiz",
+			steps: append(startQuiz[:len(startQuiz):len(startQuiz)],
+				step{press: "Yes", want: "Correct!"},
+				step{want: "Question 2 of 2"},
+				step{press: "Yes", want: "Correct!"},
+				step{want: "Your score"},
+				step{press: "No", want: "This quiz is no longer active"},
+			),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newTestBot(t, testQuizRepository(t, language)).run(t, test.steps)
+		})
+	}
+}
+
+func TestTextAnswers(t *testing.T) {
+	language := "texting"
+	repo := service.NewMemoryQuizRepository()
+	question := &models.QuizQuestion{
+		Language:      language,
+		Category:      "Testing",
+		Difficulty:    "beginner",
+		Type:          models.QuestionTypeText,
+		QuestionText:  "Which keyword declares a constant?",
+		CorrectAnswer: "const",
+		Explanation:   "It is a test question.",
+	}
+	if _, err := repo.UpsertQuestion(context.Background(), question); err != nil {
+		t.Fatalf("creating test question: %v", err)
+	}
+
+	t.Run("answer a question on screen", func(t *testing.T) {
+		newTestBot(t, repo).run(t, []step{
+			{send: "/prepare", want: "Choose a programming language"},
+			{press: formatLanguageName(language), want: "How would you like to take the"},
+			{press: "Practice", want: "Starting a new " + formatLanguageName(language) + " quiz"},
+			{want: "Question 1 of 1"},
+			{send: "const", want: "Correct!"},
+		})
+	})
+
+	t.Run("message while no question waits for an answer", func(t *testing.T) {
+		b := newTestBot(t, repo)
+		questions, err := b.quizService.GetQuestionsByLanguage(context.Background(), language, 10)
+		if err != nil {
+			t.Fatalf("getting questions: %v", err)
+		}
+		if _, err := b.quizService.CreateQuizSession(context.Background(), b.user.ID, language, questions, models.QuizOptions{}); err != nil {
+			t.Fatalf("creating session: %v", err)
+		}
+
+		b.run(t, []step{
+			{send: "const", want: "Please use the buttons or type /start to begin."},
+		})
+	})
+}
+
+func TestAnswerAfterSkip(t *testing.T) {
+	language := "testing"
+	languageName := formatLanguageName(language)
+	b := newTestBot(t, testQuizRepository(t, language))
+
+	b.run(t, []step{
+		{send: "/prepare", want: "Choose a programming language"},
+		{press: languageName, want: "How would you like to take the " + languageName + " quiz?"},
+		{press: "Practice", want: "Starting a new " + languageName + " quiz with 2 questions"},
+		{want: "Question 1 of 2"},
+	})
+	skipped, answer := b.messenger.lastButton(t, "Yes")
+
+	b.run(t, []step{
+		{press: "⏭ Skip", want: "Skipped - this question will come back at the end of the quiz."},
+		{want: "Question 1 of 2"},
+	})
+
+	// The next question now has the index of the skipped one, and the answer isn't meant for it
+	b.dispatchUpdate(b.callbackUpdate(skipped, answer))
+	b.run(t, []step{
+		{want: "This question was skipped."},
+		{press: "No", want: "Incorrect"},
+		{want: "Question 2 of 2"},
+		{press: "Yes", want: "Correct!"},
+		{want: "Your score: 50.0%"},
+	})
+}
+
+// failingQuizRepository is a quiz repository whose saves fail while fail is set
+type failingQuizRepository struct {
+	service.QuizRepository
+	fail atomic.Bool
+}
+
+func (r *failingQuizRepository) SaveProgress(ctx context.Context, session *models.QuizSession, questionIndex int, answer *models.QuizAnswer) error {
+	if r.fail.Load() {
+		return errors.New("database is down")
+	}
+	return r.QuizRepository.SaveProgress(ctx, session, questionIndex, answer)
+}
+
+func TestTimedQuestionKeepsRunningWhenAnAnswerIsNotSaved(t *testing.T) {
+	language := "testing"
+	languageName := formatLanguageName(language)
+	repo := &failingQuizRepository{QuizRepository: testQuizRepository(t, language)}
+	b := newTestBot(t, repo)
+
+	b.run(t, []step{
+		{send: "/prepare", want: "Choose a programming language"},
+		{press: languageName, want: "How would you like to take the " + languageName + " quiz?"},
+		{press: "⏱ Timed", want: "Starting a new timed " + languageName + " quiz with 2 questions"},
+		{want: "Question 1 of 2"},
+	})
+
+	session, err := b.quizService.GetActiveQuizSession(context.Background(), b.user.ID)
+	if err != nil || session == nil {
+		t.Fatalf("GetActiveQuizSession = %v, %v, want the timed quiz", session, err)
+	}
+
+	repo.fail.Store(true)
+	b.run(t, []step{
+		{press: "Yes", want: "Sorry, I couldn't record your answer."},
+		{press: "⏭ Skip", want: "Sorry, I couldn't skip the question."},
+	})
+	if b.runningQuestionTimer(session.ID) == nil {
+		t.Fatal("the countdown stopped although neither the answer nor the skip was saved")
+	}
+
+	repo.fail.Store(false)
+	b.run(t, []step{
+		{press: "Yes", want: "Correct!"},
+		{want: "Question 2 of 2"},
+	})
+}
@@ -0,0 +1,166 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxQuizSkips is how many questions a user can skip in one quiz
+const maxQuizSkips = 3
+
+// quizActionRows returns the Bookmark, Hint and Skip buttons for the current question of a session.
+// Group quizzes and duels get no hints or skips, so every player answers under the same rules.
+func quizActionRows(session *models.QuizSession, question *models.QuizQuestion) [][]tgbotapi.InlineKeyboardButton {
+	row := []tgbotapi.InlineKeyboardButton{bookmarkButton(question.ID)}
+	if session.IsGroup() || session.DuelID != nil {
+		return [][]tgbotapi.InlineKeyboardButton{row}
+	}
+
+	if !session.HintUsed && question.HasHint() {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("💡 Hint", fmt.Sprintf("quiz:hint:%d:%d:%d", session.ID, session.CurrentQuestionIndex, question.ID)))
+	}
+
+	// Skipping the last question would only show it again
+	if session.Skips < maxQuizSkips && session.CurrentQuestionIndex < len(session.QuestionIDs)-1 {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("⏭ Skip", fmt.Sprintf("quiz:skip:%d:%d:%d", session.ID, session.CurrentQuestionIndex, question.ID)))
+	}
+
+	return [][]tgbotapi.InlineKeyboardButton{row}
+}
+
+// formatHint describes the hint used for a question and returns the options it removed.
+// Questions with a hint of their own show it; other questions get a 50/50 elimination.
+func formatHint(session *models.QuizSession, question *models.QuizQuestion) (string, map[int]bool) {
+	if question.Hint != "" {
+		return "💡 *Hint:* " + question.Hint, map[int]bool{}
+	}
+
+	eliminated := question.EliminatedOptions(int64(session.ID)<<32 | int64(question.ID))
+
+	var removed []string
+	for position, optionIndex := range session.OptionOrder(len(question.AnswerOptions)) {
+		if eliminated[optionIndex] {
+			if usesLetteredOptions(question) {
+				removed = append(removed, optionLetter(position))
+			} else {
+				removed = append(removed, formatOptionText(question.AnswerOptions[optionIndex]))
+			}
+		}
+	}
+
+	return "💡 *50/50:* removed " + strings.Join(removed, ", "), eliminated
+}
+
+// useQuizHint shows the hint for the current question of a session
+func (b *Bot) useQuizHint(ctx context.Context, query *tgbotapi.CallbackQuery, userID int64, sessionID int, questionIndex int, questionID int) {
+	chatID := query.Message.Chat.ID
+
+	session, question := b.currentQuizQuestion(ctx, chatID, userID, sessionID, questionIndex, questionID)
+	if session == nil {
+		return
+	}
+
+	if session.IsGroup() || session.DuelID != nil || !question.HasHint() {
+		b.sendMessage(chatID, "Hints aren't available for this question.", nil)
+		return
+	}
+
+	// In timed mode a hint can only be used while the countdown is running
+	if session.Timed && b.runningQuestionTimer(session.ID) == nil {
+		b.sendMessage(chatID, "⏰ Time is up for this question. Type /prepare to continue your quiz.", nil)
+		return
+	}
+
+	marked, err := b.quizService.MarkHintUsed(ctx, session)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error marking hint as used", "session_id", session.ID, "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't show the hint. Please try again later.", nil)
+		return
+	}
+
+	if !marked {
+		b.sendMessage(chatID, "You already used the hint for this question.", nil)
+		return
+	}
+
+	messageText, keyboard := renderQuizQuestion(session, question)
+
+	text := messageText
+	if session.Timed {
+		if timer := b.runningQuestionTimer(session.ID); timer != nil && session.QuestionSentAt != nil {
+			b.updateTimerText(session.ID, messageText)
+			b.updateTimerKeyboard(session.ID, keyboard)
+			remaining := timer.limit - time.Since(*session.QuestionSentAt)
+			if remaining > 0 {
+				text = formatCountdown(messageText, remaining)
+			}
+		}
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, text)
+	edit.ParseMode = "Markdown"
+	if len(keyboard.InlineKeyboard) > 0 {
+		edit.ReplyMarkup = &keyboard
+	}
+	b.send(edit)
+}
+
+// skipQuizQuestion moves the current question of a session to the end of the quiz and sends the next one
+func (b *Bot) skipQuizQuestion(ctx context.Context, query *tgbotapi.CallbackQuery, userID int64, sessionID int, questionIndex int, questionID int) {
+	chatID := query.Message.Chat.ID
+
+	session, question := b.currentQuizQuestion(ctx, chatID, userID, sessionID, questionIndex, questionID)
+	if session == nil {
+		return
+	}
+
+	if session.IsGroup() || session.DuelID != nil || session.Skips >= maxQuizSkips ||
+		session.CurrentQuestionIndex >= len(session.QuestionIDs)-1 {
+		b.sendMessage(chatID, "You can't skip this question.", nil)
+		return
+	}
+
+	// Like an answer, a skip only counts while the countdown is running, which is stopped once the skip is saved
+	var timer *questionTimer
+	if session.Timed {
+		timer = b.runningQuestionTimer(session.ID)
+		if timer == nil {
+			b.sendMessage(chatID, "⏰ Time is up for this question. Type /prepare to continue your quiz.", nil)
+			return
+		}
+	}
+
+	skipped, err := b.quizService.SkipQuestion(ctx, session, question.ID, session.TimeToAnswer(time.Now()))
+	if err != nil {
+		slog.ErrorContext(ctx, "Error skipping question", "session_id", session.ID, "error", err)
+		b.sendMessage(chatID, "Sorry, I couldn't skip the question. Please try again later.", nil)
+		return
+	}
+
+	if !skipped {
+		b.sendMessage(chatID, "This question was already answered.", nil)
+		return
+	}
+
+	// Drop the answer buttons from the skipped question
+	if timer != nil {
+		b.stopQuestionTimer(session.ID)
+
+		edit := tgbotapi.NewEditMessageText(chatID, query.Message.MessageID, timer.text+"\n\n⏭ *Skipped* - it will come back at the end.")
+		edit.ParseMode = "Markdown"
+		b.send(edit)
+	} else {
+		b.send(tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, tgbotapi.InlineKeyboardMarkup{
+			InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{},
+		}))
+		b.sendMessage(chatID, "⏭ Skipped - this question will come back at the end of the quiz.", nil)
+	}
+
+	b.sendQuizQuestion(ctx, chatID, userID, session)
+}
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/logging"
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Telegram limits for quiz polls
+const (
+	pollQuestionLimit    = 300
+	pollOptionLimit      = 100
+	pollExplanationLimit = 200
+	pollMaxOptions       = 10
+)
+
+// canRenderAsPoll reports whether a question fits into a native quiz poll.
+// Quiz polls have exactly one correct option and short plain-text options,
+// other questions fall back to inline keyboards.
+func canRenderAsPoll(question *models.QuizQuestion) bool {
+	if question.Type != models.QuestionTypeSingle {
+		return false
+	}
+
+	if len(question.AnswerOptions) < 2 || len(question.AnswerOptions) > pollMaxOptions {
+		return false
+	}
+
+	for _, option := range question.AnswerOptions {
+		if len([]rune(option)) > pollOptionLimit {
+			return false
+		}
+	}
+	return true
+}
+
+// pollShowsFeedback reports whether the poll itself already tells the user the
+// correct answer and explanation, so no separate feedback message is needed
+func pollShowsFeedback(session *models.QuizSession, question *models.QuizQuestion) bool {
+	return session.RenderMode == models.RenderModePoll && canRenderAsPoll(question) &&
+		len([]rune(question.Explanation)) <= pollExplanationLimit
+}
+
+// sendQuizPoll sends the current question of a session as a native quiz poll
+func (b *Bot) sendQuizPoll(ctx context.Context, chatID int64, session *models.QuizSession, question *models.QuizQuestion) {
+	ctx = logging.With(ctx, "chat_id", chatID, "session_id", session.ID)
+
+	header := fmt.Sprintf("Question %d of %d", session.CurrentQuestionIndex+1, len(session.QuestionIDs))
+	pollQuestion := header + "\n\n" + question.QuestionText
+
+	// Long questions go into a message of their own, with a short poll below
+	if len([]rune(pollQuestion)) > pollQuestionLimit {
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("*%s*\n\n%s", header, question.QuestionText))
+		msg.ParseMode = "Markdown"
+		b.send(msg)
+		pollQuestion = header
+	}
+
+	// Options are shown in the session's shuffled order, so option IDs in
+	// poll answers are displayed positions just like keyboard callbacks
+	order := session.OptionOrder(len(question.AnswerOptions))
+	var options []string
+	correctPosition := 0
+	for position, optionIndex := range order {
+		options = append(options, question.AnswerOptions[optionIndex])
+		if question.IsCorrectOption(optionIndex) {
+			correctPosition = position
+		}
+	}
+
+	poll := tgbotapi.NewPoll(chatID, pollQuestion, options...)
+	poll.Type = "quiz"
+	poll.IsAnonymous = false // anonymous polls don't report who answered
+	poll.CorrectOptionID = int64(correctPosition)
+	if len([]rune(question.Explanation)) <= pollExplanationLimit {
+		poll.Explanation = question.Explanation
+	}
+
+	// The button stays below the poll once it shows the result,
+	// as there may be no feedback message to bookmark the question from
+	poll.ReplyMarkup = bookmarkKeyboard(question.ID)
+
+	// Answers are matched to the poll once it was sent
+	b.sendThen(chatID, poll, func(ctx context.Context, sent tgbotapi.Message) {
+		ctx = logging.With(ctx, "chat_id", chatID, "session_id", session.ID)
+
+		if sent.Poll == nil {
+			slog.ErrorContext(ctx, "Error sending quiz poll: no poll in the sent message", "question_id", question.ID)
+			b.sendMessage(chatID, "Sorry, I couldn't send the question. Please try again later.", nil)
+			return
+		}
+
+		err := b.quizService.SaveQuizPoll(ctx, &models.QuizPoll{
+			PollID:        sent.Poll.ID,
+			SessionID:     session.ID,
+			QuestionIndex: session.CurrentQuestionIndex,
+			ChatID:        chatID,
+			MessageID:     sent.MessageID,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Error saving quiz poll", "error", err)
+		}
+
+		// Remember when the question was shown to measure the time to answer
+		if err := b.quizService.MarkQuestionSent(ctx, session, time.Now()); err != nil {
+			slog.ErrorContext(ctx, "Error marking question as sent", "error", err)
+		}
+	})
+}
+
+// handlePollAnswer processes an answer to a quiz poll sent for a session question
+func (b *Bot) handlePollAnswer(ctx context.Context, answer *tgbotapi.PollAnswer) {
+	// Quiz poll answers can't be changed, but ignore retracted votes anyway
+	if len(answer.OptionIDs) == 0 {
+		return
+	}
+
+	poll, err := b.quizService.GetQuizPoll(ctx, answer.PollID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving quiz poll", "error", err)
+		return
+	}
+
+	if poll == nil {
+		return
+	}
+
+	user := b.saveUserInfo(ctx, &answer.User)
+
+	session, err := b.quizService.GetActiveQuizSession(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving active session", "error", err)
+		return
+	}
+
+	// Answers to polls of finished quizzes or earlier questions don't count
+	if session == nil || session.ID != poll.SessionID || session.CurrentQuestionIndex != poll.QuestionIndex {
+		return
+	}
+
+	questionID := session.QuestionIDs[session.CurrentQuestionIndex]
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
+		return
+	}
+
+	position := answer.OptionIDs[0]
+	if position < 0 || position >= len(question.AnswerOptions) {
+		return
+	}
+
+	answerIndex := session.OptionOrder(len(question.AnswerOptions))[position]
+	isCorrect := question.IsCorrectOption(answerIndex)
+	b.submitQuizAnswer(ctx, poll.ChatID, user.ID, session, question, &answerIndex, question.AnswerOptions[answerIndex], isCorrect)
+}
@@ -0,0 +1,222 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// defaultQuestionTimeLimit is used for difficulties without a time limit in the quiz settings
+const defaultQuestionTimeLimit = 45 * time.Second
+
+// timerUpdateInterval is how often the countdown in a timed question is refreshed.
+// Telegram throttles message edits, so this should not be too small.
+const timerUpdateInterval = 5 * time.Second
+
+// questionTimer tracks the countdown of a single question in a timed quiz
+type questionTimer struct {
+	chatID        int64
+	userID        int64
+	sessionID     int
+	questionIndex int
+	questionID    int // a skip moves another question to the same index
+	messageID     int
+	text          string
+	keyboard      tgbotapi.InlineKeyboardMarkup
+	limit         time.Duration
+	group         bool // the question is open to every member of a group chat
+	stop          chan struct{}
+}
+
+// questionTimeLimit returns the time allowed to answer a question of the given difficulty
+func (b *Bot) questionTimeLimit(difficulty string) time.Duration {
+	if limit, ok := b.quiz.TimeLimits[difficulty]; ok {
+		return limit
+	}
+	return defaultQuestionTimeLimit
+}
+
+// formatCountdown appends the remaining time to a question message
+func formatCountdown(text string, remaining time.Duration) string {
+	seconds := int(remaining.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%s\n\n⏳ *%ds left*", text, seconds)
+}
+
+// startQuestionTimer starts the countdown for the question that was just sent.
+// Any timer still running for the same session is stopped first.
+func (b *Bot) startQuestionTimer(chatID int64, userID int64, session *models.QuizSession, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup, limit time.Duration) {
+	b.stopQuestionTimer(session.ID)
+
+	timer := &questionTimer{
+		chatID:        chatID,
+		userID:        userID,
+		sessionID:     session.ID,
+		questionIndex: session.CurrentQuestionIndex,
+		questionID:    session.QuestionIDs[session.CurrentQuestionIndex],
+		messageID:     messageID,
+		text:          text,
+		keyboard:      keyboard,
+		limit:         limit,
+		group:         session.IsGroup(),
+		stop:          make(chan struct{}),
+	}
+
+	b.timersMutex.Lock()
+	b.timers[session.ID] = timer
+	b.timersMutex.Unlock()
+
+	go b.runQuestionTimer(timer)
+}
+
+// runQuestionTimer refreshes the countdown until the question is answered or the time runs out
+func (b *Bot) runQuestionTimer(timer *questionTimer) {
+	deadline := time.Now().Add(timer.limit)
+	expired := time.NewTimer(timer.limit)
+	defer expired.Stop()
+	ticker := time.NewTicker(timerUpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer.stop:
+			return
+		case <-ticker.C:
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				continue
+			}
+			b.timersMutex.Lock()
+			text, keyboard := timer.text, timer.keyboard
+			b.timersMutex.Unlock()
+
+			edit := tgbotapi.NewEditMessageText(timer.chatID, timer.messageID, formatCountdown(text, remaining))
+			edit.ParseMode = "Markdown"
+			if len(keyboard.InlineKeyboard) > 0 {
+				edit.ReplyMarkup = &keyboard
+			}
+			b.send(edit)
+		case <-expired.C:
+			if !b.claimQuestionTimer(timer) {
+				return
+			}
+			// The expiry is handled in order with the updates of the chat
+			b.dispatcher.Submit(timer.chatID, func(ctx context.Context) {
+				if timer.group {
+					b.closeGroupQuestion(ctx, timer)
+				} else {
+					b.expireQuestion(ctx, timer)
+				}
+			})
+			return
+		}
+	}
+}
+
+// stopQuestionTimer stops the countdown of a session and returns it.
+// It returns nil if no countdown was running, e.g. because the time already ran out.
+func (b *Bot) stopQuestionTimer(sessionID int) *questionTimer {
+	b.timersMutex.Lock()
+	defer b.timersMutex.Unlock()
+
+	timer, exists := b.timers[sessionID]
+	if !exists {
+		return nil
+	}
+	delete(b.timers, sessionID)
+	close(timer.stop)
+	return timer
+}
+
+// runningQuestionTimer returns the countdown of a session, or nil if no countdown is running
+func (b *Bot) runningQuestionTimer(sessionID int) *questionTimer {
+	b.timersMutex.Lock()
+	defer b.timersMutex.Unlock()
+
+	return b.timers[sessionID]
+}
+
+// updateTimerKeyboard replaces the keyboard shown with the countdown,
+// so refreshing the countdown keeps the options a user has toggled
+func (b *Bot) updateTimerKeyboard(sessionID int, keyboard tgbotapi.InlineKeyboardMarkup) {
+	b.timersMutex.Lock()
+	defer b.timersMutex.Unlock()
+
+	if timer, exists := b.timers[sessionID]; exists {
+		timer.keyboard = keyboard
+	}
+}
+
+// updateTimerText replaces the question text shown with the countdown,
+// e.g. to list the group members who already answered
+func (b *Bot) updateTimerText(sessionID int, text string) {
+	b.timersMutex.Lock()
+	defer b.timersMutex.Unlock()
+
+	if timer, exists := b.timers[sessionID]; exists {
+		timer.text = text
+	}
+}
+
+// claimQuestionTimer removes an expired timer, reporting whether it was still running.
+// This makes sure an answer and an expiry are never both processed for the same question.
+func (b *Bot) claimQuestionTimer(timer *questionTimer) bool {
+	b.timersMutex.Lock()
+	defer b.timersMutex.Unlock()
+
+	if b.timers[timer.sessionID] != timer {
+		return false
+	}
+	delete(b.timers, timer.sessionID)
+	return true
+}
+
+// expireQuestion marks an unanswered question as wrong and moves on to the next one
+func (b *Bot) expireQuestion(ctx context.Context, timer *questionTimer) {
+	session, err := b.quizService.GetActiveQuizSession(ctx, timer.userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving active session", "error", err)
+		return
+	}
+
+	// The user may have started another quiz or skipped the question in the meantime
+	if session == nil || session.ID != timer.sessionID || session.CurrentQuestionIndex != timer.questionIndex ||
+		session.QuestionIDs[timer.questionIndex] != timer.questionID {
+		return
+	}
+
+	questionID := session.QuestionIDs[session.CurrentQuestionIndex]
+	question, err := b.quizService.GetQuestionByID(ctx, questionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error fetching question", "question_id", questionID, "error", err)
+		return
+	}
+
+	// An answer that arrived at the same time wins
+	submitted, err := b.quizService.SubmitAnswer(ctx, session, timer.questionIndex, nil, "", false, &timer.limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error submitting expired answer", "error", err)
+		return
+	}
+	if !submitted {
+		return
+	}
+
+	// Remove the answer buttons from the expired question
+	edit := tgbotapi.NewEditMessageText(timer.chatID, timer.messageID, timer.text+"\n\n⏰ *Time's up!*")
+	edit.ParseMode = "Markdown"
+	b.send(edit)
+
+	feedbackMessage := fmt.Sprintf("⏰ *Time's up!*\n\nThe correct answer is: %s\n\n", formatCorrectAnswer(question))
+	feedbackMessage += "*Explanation:*\n" + question.Explanation
+
+	msg := tgbotapi.NewMessage(timer.chatID, feedbackMessage)
+	msg.ParseMode = "Markdown"
+	msg.ReplyMarkup = bookmarkKeyboard(question.ID)
+	b.send(msg)
+
+	b.advanceQuiz(ctx, timer.chatID, timer.userID, session, timer.questionIndex)
+}
@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleStatsCommand shows the user's quiz results per language and their daily challenge streak
+func (b *Bot) handleStatsCommand(ctx context.Context, message *tgbotapi.Message) {
+	user := b.saveUserInfo(ctx, message.From)
+
+	stats, err := b.quizService.GetUserQuizStats(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error retrieving quiz stats", "error", err)
+		b.sendMessage(message.Chat.ID, "Sorry, I couldn't load your stats. Please try again later.", nil)
+		return
+	}
+
+	text := "📊 *Your Quiz Stats*\n\n"
+
+	if len(stats) == 0 {
+		text += "You haven't completed any quizzes yet. Type /prepare to start one.\n\n"
+	}
+
+	languages := make([]string, 0, len(stats))
+	for language := range stats {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	for _, language := range languages {
+		languageStats := stats[language]
+		total := languageStats["total_questions"]
+		correct := languageStats["correct_answers"]
+
+		text += fmt.Sprintf("*%s*\n", formatLanguageName(language))
+		text += fmt.Sprintf("Quizzes completed: %d\n", languageStats["completed_quizzes"])
+		if total > 0 {
+			text += fmt.Sprintf("Correct answers: %d/%d (%.0f%%)\n", correct, total, float64(correct)/float64(total)*100)
+		}
+		if avg := languageStats["avg_time_to_answer_ms"]; avg > 0 {
+			text += fmt.Sprintf("Average time per answer: %.1fs\n", float64(avg)/1000)
+		}
+		text += "\n"
+	}
+
+	if b.db != nil {
+		streak, err := b.dailyService.GetDailyStreak(ctx, user.ID, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "Error retrieving daily streak", "error", err)
+		} else {
+			text += "🔥 " + formatDailyStreak(streak)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = "Markdown"
+	b.send(msg)
+}
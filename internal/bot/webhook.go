@@ -0,0 +1,182 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Webhook server settings
+const (
+	webhookSecretHeader  = "X-Telegram-Bot-Api-Secret-Token"
+	webhookMaxBodySize   = 1 << 20 // updates are much smaller; larger bodies are rejected
+	webhookReadTimeout   = 10 * time.Second
+	webhookWriteTimeout  = 10 * time.Second
+	webhookCloseTimeout  = 5 * time.Second
+	webhookDefaultListen = ":8443"
+)
+
+// WebhookConfig configures receiving updates through a webhook instead of long polling
+type WebhookConfig struct {
+	URL         string // public HTTPS URL Telegram posts updates to; its path is the one served
+	ListenAddr  string // address of the HTTP server, ":8443" if empty
+	SecretToken string // expected in the secret token header of every update; generated if empty
+	CertFile    string // TLS certificate; with KeyFile the server uses HTTPS, and the certificate
+	KeyFile     string // is uploaded to Telegram so self-signed certificates work
+}
+
+// StartWebhook registers the webhook with Telegram and serves updates until ctx is done.
+// The webhook is deleted again before it returns. Updates are handled by the same dispatcher
+// as in polling mode, so they keep being handled until Shutdown is called.
+func (b *Bot) StartWebhook(ctx context.Context, config WebhookConfig) error {
+	slog.Info("Authorized", "bot", b.self.UserName)
+
+	webhookURL, err := url.Parse(config.URL)
+	if err != nil || webhookURL.Scheme != "https" || webhookURL.Host == "" {
+		return fmt.Errorf("invalid webhook URL %q: Telegram only sends updates to https URLs", config.URL)
+	}
+
+	if config.SecretToken == "" {
+		config.SecretToken, err = generateSecretToken()
+		if err != nil {
+			return err
+		}
+	}
+
+	if config.ListenAddr == "" {
+		config.ListenAddr = webhookDefaultListen
+	}
+
+	path := webhookURL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, b.webhookHandler(config.SecretToken))
+
+	server := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  webhookReadTimeout,
+		WriteTimeout: webhookWriteTimeout,
+	}
+
+	// Listen before serving in the background, so a busy address fails here
+	// rather than after Telegram was asked to send updates to it
+	listener, err := net.Listen("tcp", config.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", config.ListenAddr, err)
+	}
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		var err error
+		if config.CertFile != "" && config.KeyFile != "" {
+			err = server.ServeTLS(listener, config.CertFile, config.KeyFile)
+		} else {
+			err = server.Serve(listener)
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- err
+		}
+	}()
+
+	// Only ask Telegram for updates once the server is listening
+	if err := b.setWebhook(config); err != nil {
+		server.Close()
+		return err
+	}
+	slog.Info("Receiving updates through a webhook", "url", webhookURL.Redacted(), "listen_addr", config.ListenAddr)
+
+	if b.db != nil {
+		go b.runDailyBroadcast(ctx)
+	}
+
+	select {
+	case <-ctx.Done():
+	case err = <-serverErrors:
+		err = fmt.Errorf("error serving webhook: %w", err)
+	}
+
+	// Telegram keeps updates sent while the webhook is gone and delivers them once it is set again
+	if _, deleteErr := b.api.Request(tgbotapi.DeleteWebhookConfig{}); deleteErr != nil {
+		slog.ErrorContext(ctx, "Error deleting webhook", "error", deleteErr)
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), webhookCloseTimeout)
+	defer cancel()
+	if closeErr := server.Shutdown(closeCtx); closeErr != nil {
+		slog.ErrorContext(ctx, "Error stopping webhook server", "error", closeErr)
+	}
+
+	return err
+}
+
+// setWebhook registers the webhook with Telegram.
+// The API library predates secret tokens, so the request is built here.
+func (b *Bot) setWebhook(config WebhookConfig) error {
+	params := tgbotapi.Params{"url": config.URL}
+	params.AddNonEmpty("secret_token", config.SecretToken)
+
+	var err error
+	if config.CertFile != "" && config.KeyFile != "" {
+		_, err = b.client.UploadFiles("setWebhook", params, []tgbotapi.RequestFile{{
+			Name: "certificate",
+			Data: tgbotapi.FilePath(config.CertFile),
+		}})
+	} else {
+		_, err = b.client.MakeRequest("setWebhook", params)
+	}
+
+	if err != nil {
+		return fmt.Errorf("error setting webhook: %w", err)
+	}
+	return nil
+}
+
+// webhookHandler accepts updates posted by Telegram and queues them on the dispatcher.
+// Requests without the secret token are rejected, so nobody else can inject updates.
+func (b *Bot) webhookHandler(secretToken string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(webhookSecretHeader)), []byte(secretToken)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, webhookMaxBodySize)).Decode(&update); err != nil {
+			slog.ErrorContext(r.Context(), "Error decoding webhook update", "error", err)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		// Handling happens in the background, Telegram only waits for the acknowledgement
+		b.dispatchUpdate(update)
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// generateSecretToken creates a random webhook secret token
+func generateSecretToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("error generating webhook secret token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,42 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	b := newTestBot(t, testQuizRepository(t, "webhook"))
+	handler := b.webhookHandler("secret")
+
+	tests := []struct {
+		name   string
+		method string
+		token  string
+		want   int
+	}{
+		{name: "no secret token", method: http.MethodPost, want: http.StatusForbidden},
+		{name: "wrong secret token", method: http.MethodPost, token: "guess", want: http.StatusForbidden},
+		{name: "right secret token", method: http.MethodPost, token: "secret", want: http.StatusOK},
+		{name: "not a post", method: http.MethodGet, token: "secret", want: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// An update without a chat, which the bot acknowledges and ignores
+			request := httptest.NewRequest(tt.method, "/webhook", strings.NewReader(`{"update_id": 1}`))
+			if tt.token != "" {
+				request.Header.Set(webhookSecretHeader, tt.token)
+			}
+
+			response := httptest.NewRecorder()
+			handler(response, request)
+
+			if response.Code != tt.want {
+				t.Errorf("got status %d, want %d", response.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+// Package config loads the bot's settings.
+//
+// Settings start from their defaults, are overridden by an optional YAML file and then by
+// environment variables. A .env file in the working directory sets environment variables
+// that aren't set already. The YAML file is given with -config or CONFIG_FILE; its keys
+// are the yaml tags below, e.g.
+//
+//	telegram_token: "123456:ABC..."
+//	transport: webhook
+//	webhook:
+//	  url: https://bot.example.com/telegram
+//	quiz:
+//	  question_count: 15
+//	  next_question_delay: 5s
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Transports the bot can receive updates through
+const (
+	TransportPolling = "polling"
+	TransportWebhook = "webhook"
+)
+
+// Log levels, from the most to the least verbose
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// Config holds every setting of the bot
+type Config struct {
+	TelegramToken string     `yaml:"telegram_token"` // TELEGRAM_BOT_TOKEN
+	DatabaseURL   string     `yaml:"database_url"`   // DATABASE_URL; without one quizzes are kept in memory
+	LogLevel      string     `yaml:"log_level"`      // LOG_LEVEL, one of LogLevels
+	Transport     string     `yaml:"transport"`      // TRANSPORT, polling or webhook; webhook if a webhook URL is set
+	Webhook       Webhook    `yaml:"webhook"`
+	Quiz          Quiz       `yaml:"quiz"`
+	Updates       Updates    `yaml:"updates"`
+	Monitoring    Monitoring `yaml:"monitoring"`
+}
+
+// Webhook configures receiving updates through a webhook
+type Webhook struct {
+	URL         string `yaml:"url"`          // WEBHOOK_URL, public HTTPS URL Telegram posts updates to
+	ListenAddr  string `yaml:"listen_addr"`  // WEBHOOK_LISTEN_ADDR, address of the built-in server
+	SecretToken string `yaml:"secret_token"` // WEBHOOK_SECRET_TOKEN; generated on every start if empty
+	CertFile    string `yaml:"cert_file"`    // WEBHOOK_CERT_FILE; with the key file the server uses HTTPS
+	KeyFile     string `yaml:"key_file"`     // WEBHOOK_KEY_FILE
+}
+
+// Quiz configures quizzes and the question bank
+type Quiz struct {
+	QuestionCount           int           `yaml:"question_count"`            // QUIZ_QUESTION_COUNT, questions per quiz
+	FirstQuestionDelay      time.Duration `yaml:"first_question_delay"`      // QUIZ_FIRST_QUESTION_DELAY, after the quiz introduction
+	NextQuestionDelay       time.Duration `yaml:"next_question_delay"`       // QUIZ_NEXT_QUESTION_DELAY, after the feedback on an answer
+	QuestionsFile           string        `yaml:"questions_file"`            // QUESTIONS_FILE, question bank loaded without a database
+	DisableInvalidQuestions bool          `yaml:"disable_invalid_questions"` // DISABLE_INVALID_QUESTIONS, on startup
+
+	// TimeLimits is the time to answer a question in timed mode by difficulty, QUIZ_TIME_LIMITS
+	// as e.g. beginner=30s,advanced=1m. Settings for some difficulties keep the defaults of the others.
+	TimeLimits map[string]time.Duration `yaml:"time_limits"`
+}
+
+// Updates configures how updates are handled
+type Updates struct {
+	Workers         int           `yaml:"workers"`          // UPDATE_WORKERS, updates handled at the same time
+	Timeout         time.Duration `yaml:"timeout"`          // UPDATE_TIMEOUT, time handling a single update may take
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // SHUTDOWN_TIMEOUT, time to finish the updates received when stopping
+}
+
+// Monitoring configures the health and metrics endpoints
+type Monitoring struct {
+	ListenAddr string `yaml:"listen_addr"` // MONITORING_LISTEN_ADDR, address of /healthz, /readyz and /metrics; empty disables them
+}
+
+// Default returns the default settings. The Telegram token has no default.
+func Default() *Config {
+	return &Config{
+		LogLevel: "info",
+		Webhook: Webhook{
+			ListenAddr: ":8443",
+		},
+		Quiz: Quiz{
+			QuestionCount:      10,
+			FirstQuestionDelay: 1 * time.Second,
+			NextQuestionDelay:  3 * time.Second,
+			TimeLimits: map[string]time.Duration{
+				"beginner":     30 * time.Second,
+				"intermediate": 45 * time.Second,
+				"advanced":     60 * time.Second,
+			},
+		},
+		Updates: Updates{
+			Workers:         16,
+			Timeout:         30 * time.Second,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Monitoring: Monitoring{
+			ListenAddr: ":9090",
+		},
+	}
+}
+
+// Load reads the settings from the YAML file at path, if any, and the environment, and validates them.
+// Without a path the file named by CONFIG_FILE is read, if it is set.
+func Load(path string) (*Config, error) {
+	// Variables already set in the environment take precedence over .env
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error loading .env: %w", err)
+	}
+
+	cfg := Default()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.readEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if cfg.Transport == "" {
+		cfg.Transport = TransportPolling
+		if cfg.Webhook.URL != "" {
+			cfg.Transport = TransportWebhook
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// readFile overrides the settings with those of a YAML file. Unknown keys are rejected.
+func (c *Config) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening config file: %w", err)
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("error reading config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readEnv overrides the settings with the environment variables that are set
+func (c *Config) readEnv(lookup func(key string) (string, bool)) error {
+	var errs []error
+
+	str := func(key string, value *string) {
+		if v, ok := lookup(key); ok {
+			*value = v
+		}
+	}
+	integer := func(key string, value *int) {
+		if v, ok := lookup(key); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a number", key, v))
+				return
+			}
+			*value = n
+		}
+	}
+	duration := func(key string, value *time.Duration) {
+		if v, ok := lookup(key); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a duration such as 3s or 1m", key, v))
+				return
+			}
+			*value = d
+		}
+	}
+	boolean := func(key string, value *bool) {
+		if v, ok := lookup(key); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not true or false", key, v))
+				return
+			}
+			*value = b
+		}
+	}
+
+	str("TELEGRAM_BOT_TOKEN", &c.TelegramToken)
+	str("DATABASE_URL", &c.DatabaseURL)
+	str("LOG_LEVEL", &c.LogLevel)
+	str("TRANSPORT", &c.Transport)
+
+	str("WEBHOOK_URL", &c.Webhook.URL)
+	str("WEBHOOK_LISTEN_ADDR", &c.Webhook.ListenAddr)
+	str("WEBHOOK_SECRET_TOKEN", &c.Webhook.SecretToken)
+	str("WEBHOOK_CERT_FILE", &c.Webhook.CertFile)
+	str("WEBHOOK_KEY_FILE", &c.Webhook.KeyFile)
+
+	integer("QUIZ_QUESTION_COUNT", &c.Quiz.QuestionCount)
+	duration("QUIZ_FIRST_QUESTION_DELAY", &c.Quiz.FirstQuestionDelay)
+	duration("QUIZ_NEXT_QUESTION_DELAY", &c.Quiz.NextQuestionDelay)
+	str("QUESTIONS_FILE", &c.Quiz.QuestionsFile)
+	boolean("DISABLE_INVALID_QUESTIONS", &c.Quiz.DisableInvalidQuestions)
+
+	if v, ok := lookup("QUIZ_TIME_LIMITS"); ok {
+		if c.Quiz.TimeLimits == nil {
+			c.Quiz.TimeLimits = make(map[string]time.Duration)
+		}
+		for _, field := range strings.Split(v, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			difficulty, limit, found := strings.Cut(field, "=")
+			d, err := time.ParseDuration(strings.TrimSpace(limit))
+			if !found || err != nil {
+				errs = append(errs, fmt.Errorf("QUIZ_TIME_LIMITS: %q is not a difficulty and duration such as beginner=30s", field))
+				continue
+			}
+			c.Quiz.TimeLimits[strings.TrimSpace(difficulty)] = d
+		}
+	}
+
+	integer("UPDATE_WORKERS", &c.Updates.Workers)
+	duration("UPDATE_TIMEOUT", &c.Updates.Timeout)
+	duration("SHUTDOWN_TIMEOUT", &c.Updates.ShutdownTimeout)
+
+	str("MONITORING_LISTEN_ADDR", &c.Monitoring.ListenAddr)
+
+	return errors.Join(errs...)
+}
+
+// telegramTokenPattern matches the tokens @BotFather hands out: the bot's ID and a secret
+var telegramTokenPattern = regexp.MustCompile(`^\d+:[A-Za-z0-9_-]+$`)
+
+// maxQuizQuestionCount is the most questions a quiz may have
+const maxQuizQuestionCount = 50
+
+// Validate checks the settings and reports every problem found
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.TelegramToken != "", "the Telegram bot token is not set")
+	check(c.TelegramToken == "" || telegramTokenPattern.MatchString(c.TelegramToken),
+		"the Telegram bot token doesn't look like one from @BotFather (<bot ID>:<secret>)")
+
+	if c.DatabaseURL != "" && strings.Contains(c.DatabaseURL, "://") {
+		dbURL, err := url.Parse(c.DatabaseURL)
+		check(err == nil && (dbURL.Scheme == "postgres" || dbURL.Scheme == "postgresql") && dbURL.Host != "",
+			"the database URL must be a postgres:// URL")
+	}
+
+	check(contains(LogLevels, c.LogLevel), "log level %q is not one of %s", c.LogLevel, strings.Join(LogLevels, ", "))
+
+	switch c.Transport {
+	case TransportPolling:
+	case TransportWebhook:
+		webhookURL, err := url.Parse(c.Webhook.URL)
+		check(err == nil && webhookURL.Scheme == "https" && webhookURL.Host != "",
+			"the webhook URL %q must be an https URL", c.Webhook.URL)
+		check(c.Webhook.ListenAddr != "", "the webhook listen address is not set")
+		check((c.Webhook.CertFile == "") == (c.Webhook.KeyFile == ""),
+			"the webhook certificate and key files must be set together")
+		check(c.Monitoring.ListenAddr == "" || c.Monitoring.ListenAddr != c.Webhook.ListenAddr,
+			"the webhook and monitoring servers can't both listen on %s", c.Webhook.ListenAddr)
+	default:
+		check(false, "transport %q is not %s or %s", c.Transport, TransportPolling, TransportWebhook)
+	}
+
+	check(c.Quiz.QuestionCount >= 1 && c.Quiz.QuestionCount <= maxQuizQuestionCount,
+		"the quiz question count %d is not between 1 and %d", c.Quiz.QuestionCount, maxQuizQuestionCount)
+	check(c.Quiz.FirstQuestionDelay >= 0, "the first question delay can't be negative")
+	check(c.Quiz.NextQuestionDelay >= 0, "the next question delay can't be negative")
+
+	difficulties := make([]string, 0, len(c.Quiz.TimeLimits))
+	for difficulty := range c.Quiz.TimeLimits {
+		difficulties = append(difficulties, difficulty)
+	}
+	sort.Strings(difficulties)
+	for _, difficulty := range difficulties {
+		check(contains(models.Difficulties, difficulty), "the time limit for %q is not for one of the difficulties %s",
+			difficulty, strings.Join(models.Difficulties, ", "))
+		check(c.Quiz.TimeLimits[difficulty] > 0, "the %s time limit must be positive", difficulty)
+	}
+
+	check(c.Updates.Workers >= 1, "at least one update worker is needed")
+	check(c.Updates.Timeout > 0, "the update timeout must be positive")
+	check(c.Updates.ShutdownTimeout > 0, "the shutdown timeout must be positive")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redacted returns the settings as YAML with the secrets masked, to be logged
+func (c *Config) Redacted() string {
+	redacted := *c
+	redacted.TelegramToken = redactToken(c.TelegramToken)
+	if c.Webhook.SecretToken != "" {
+		redacted.Webhook.SecretToken = redactedValue
+	}
+	if dbURL, err := url.Parse(c.DatabaseURL); err == nil && strings.Contains(c.DatabaseURL, "://") {
+		redacted.DatabaseURL = dbURL.Redacted()
+	} else if c.DatabaseURL != "" {
+		redacted.DatabaseURL = redactedValue
+	}
+
+	out, err := yaml.Marshal(redacted)
+	if err != nil {
+		return fmt.Sprintf("error formatting config: %v", err)
+	}
+	return string(out)
+}
+
+// redactedValue replaces secrets in Redacted
+const redactedValue = "[redacted]"
+
+// redactToken keeps the bot ID of a Telegram token, which is public, and masks the secret
+func redactToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	if id, _, found := strings.Cut(token, ":"); found {
+		return id + ":" + redactedValue
+	}
+	return redactedValue
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
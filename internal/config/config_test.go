@@ -0,0 +1,169 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testToken = "123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11"
+
+// clearEnv unsets the variables Load reads, so the environment running the tests doesn't leak in
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		"CONFIG_FILE", "TELEGRAM_BOT_TOKEN", "DATABASE_URL", "LOG_LEVEL", "TRANSPORT",
+		"WEBHOOK_URL", "WEBHOOK_LISTEN_ADDR", "WEBHOOK_SECRET_TOKEN", "WEBHOOK_CERT_FILE", "WEBHOOK_KEY_FILE",
+		"QUIZ_QUESTION_COUNT", "QUIZ_FIRST_QUESTION_DELAY", "QUIZ_NEXT_QUESTION_DELAY", "QUIZ_TIME_LIMITS",
+		"QUESTIONS_FILE", "DISABLE_INVALID_QUESTIONS", "UPDATE_WORKERS", "UPDATE_TIMEOUT", "SHUTDOWN_TIMEOUT", "MONITORING_LISTEN_ADDR",
+	} {
+		if value, ok := os.LookupEnv(key); ok {
+			os.Unsetenv(key)
+			t.Cleanup(func() { os.Setenv(key, value) })
+		}
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(file, []byte(`
+telegram_token: "1:from-file"
+log_level: warn
+quiz:
+  question_count: 15
+  next_question_delay: 5s
+  time_limits:
+    beginner: 20s
+    intermediate: 40s
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TELEGRAM_BOT_TOKEN", testToken)
+	t.Setenv("QUIZ_FIRST_QUESTION_DELAY", "0s")
+	t.Setenv("QUIZ_TIME_LIMITS", "intermediate=50s")
+
+	cfg, err := Load(file)
+	if err != nil {
+		t.Fatalf("loading: %v", err)
+	}
+
+	// The environment overrides the file, which overrides the defaults
+	if cfg.TelegramToken != testToken {
+		t.Errorf("got token %q, want the one from the environment", cfg.TelegramToken)
+	}
+	if cfg.LogLevel != "warn" || cfg.Quiz.QuestionCount != 15 || cfg.Quiz.NextQuestionDelay != 5*time.Second {
+		t.Errorf("settings from the file weren't read: %+v", cfg)
+	}
+	if cfg.Quiz.FirstQuestionDelay != 0 {
+		t.Errorf("got first question delay %s, want 0", cfg.Quiz.FirstQuestionDelay)
+	}
+	// Time limits are overridden one difficulty at a time
+	limits := cfg.Quiz.TimeLimits
+	if limits["beginner"] != 20*time.Second || limits["intermediate"] != 50*time.Second || limits["advanced"] != time.Minute {
+		t.Errorf("got time limits %v, want 20s, 50s and the default 1m", limits)
+	}
+	if cfg.Updates.Workers != 16 || cfg.Updates.ShutdownTimeout != 20*time.Second {
+		t.Errorf("defaults weren't kept: %+v", cfg.Updates)
+	}
+	if cfg.Transport != TransportPolling {
+		t.Errorf("got transport %q without a webhook URL, want polling", cfg.Transport)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		env  map[string]string
+		want []string // parts of the error
+	}{
+		{
+			name: "missing token",
+			env:  map[string]string{},
+			want: []string{"token is not set"},
+		},
+		{
+			name: "malformed values",
+			env:  map[string]string{"TELEGRAM_BOT_TOKEN": testToken, "QUIZ_QUESTION_COUNT": "ten", "UPDATE_TIMEOUT": "30"},
+			want: []string{"QUIZ_QUESTION_COUNT", "UPDATE_TIMEOUT"},
+		},
+		{
+			name: "every problem is reported",
+			env: map[string]string{
+				"TELEGRAM_BOT_TOKEN":  "not-a-token",
+				"LOG_LEVEL":           "verbose",
+				"QUIZ_QUESTION_COUNT": "0",
+				"DATABASE_URL":        "mysql://localhost/bot",
+			},
+			want: []string{"@BotFather", "log level", "question count", "postgres://"},
+		},
+		{
+			name: "bad time limits",
+			env: map[string]string{
+				"TELEGRAM_BOT_TOKEN": testToken,
+				"QUIZ_TIME_LIMITS":   "beginner=0s,advanced=-5s,expert=1m",
+			},
+			want: []string{"beginner time limit must be positive", "advanced time limit must be positive", `"expert"`},
+		},
+		{
+			name: "malformed time limits",
+			env:  map[string]string{"TELEGRAM_BOT_TOKEN": testToken, "QUIZ_TIME_LIMITS": "beginner:30s"},
+			want: []string{"QUIZ_TIME_LIMITS"},
+		},
+		{
+			name: "webhook without https",
+			env:  map[string]string{"TELEGRAM_BOT_TOKEN": testToken, "WEBHOOK_URL": "http://bot.example.com"},
+			want: []string{"https"},
+		},
+		{
+			name: "unknown transport",
+			env:  map[string]string{"TELEGRAM_BOT_TOKEN": testToken, "TRANSPORT": "carrier-pigeon"},
+			want: []string{"transport"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			clearEnv(t)
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load("")
+			if err == nil {
+				t.Fatal("loading succeeded")
+			}
+			for _, want := range test.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q doesn't mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.TelegramToken = testToken
+	cfg.DatabaseURL = "postgres://bot:hunter2@db:5432/bot?sslmode=disable"
+	cfg.Webhook.SecretToken = "s3cret"
+
+	redacted := cfg.Redacted()
+	for _, secret := range []string{"ABC-DEF1234", "hunter2", "s3cret"} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("redacted config contains %q:\n%s", secret, redacted)
+		}
+	}
+	for _, kept := range []string{"123456:", "bot:xxxxx@db:5432", "next_question_delay: 3s"} {
+		if !strings.Contains(redacted, kept) {
+			t.Errorf("redacted config doesn't contain %q:\n%s", kept, redacted)
+		}
+	}
+
+	if cfg.TelegramToken != testToken {
+		t.Error("Redacted changed the config")
+	}
+}
@@ -0,0 +1,195 @@
+// Package dispatcher runs bot work on a pool of workers.
+//
+// Jobs are submitted with a key, usually the ID of the chat they belong to.
+// Jobs with different keys run concurrently, while jobs with the same key run
+// one after another in the order they were submitted, so the updates of one
+// user or chat are never handled out of order.
+package dispatcher
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a unit of work. Its context is cancelled when the job runs out of time
+// or the dispatcher shuts down without waiting for it.
+type Job func(ctx context.Context)
+
+// Dispatcher runs keyed jobs on a fixed number of workers
+type Dispatcher struct {
+	mutex   sync.Mutex
+	ready   *sync.Cond
+	queues  map[int64][]Job // pending jobs by key; a key is present while it has a job queued or running
+	keys    []int64         // keys with pending jobs and no running job, oldest first
+	delayed map[*time.Timer]delayedJob
+	closed  bool
+	done    sync.WaitGroup
+
+	timeout time.Duration      // time a single job may take
+	ctx     context.Context    // parent of the job contexts
+	cancel  context.CancelFunc // cancels the jobs still running when shutdown gives up
+}
+
+// delayedJob is a job waiting for its delay to pass
+type delayedJob struct {
+	key int64
+	job Job
+}
+
+// New creates a Dispatcher and starts its workers.
+// Every job gets a context that times out after timeout.
+func New(workers int, timeout time.Duration) *Dispatcher {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		queues:  make(map[int64][]Job),
+		delayed: make(map[*time.Timer]delayedJob),
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	d.ready = sync.NewCond(&d.mutex)
+
+	d.done.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.work()
+	}
+
+	return d
+}
+
+// Submit queues a job behind the other jobs of its key.
+// It returns false if the dispatcher was shut down and the job won't run.
+func (d *Dispatcher) Submit(key int64, job Job) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.closed {
+		slog.Warn("Dropped a job: the dispatcher is shut down", "key", key)
+		return false
+	}
+
+	d.enqueue(key, job)
+	return true
+}
+
+// SubmitAfter queues a job once delay has passed, without holding up a worker in the meantime.
+// The job runs behind the jobs submitted for its key before it was queued.
+// Jobs still waiting when the dispatcher shuts down are queued right away, so they aren't lost.
+func (d *Dispatcher) SubmitAfter(key int64, delay time.Duration, job Job) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.closed {
+		slog.Warn("Dropped a delayed job: the dispatcher is shut down", "key", key, "delay", delay)
+		return
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+
+		// Shutdown may have queued the job already
+		if _, waiting := d.delayed[timer]; !waiting {
+			return
+		}
+		delete(d.delayed, timer)
+		d.enqueue(key, job)
+	})
+	d.delayed[timer] = delayedJob{key: key, job: job}
+}
+
+// Shutdown stops accepting jobs and waits until the workers finished every job already queued,
+// including delayed jobs. If ctx is done first, the jobs still running are cancelled
+// and Shutdown returns the context's error without waiting for them.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mutex.Lock()
+	for timer, delayed := range d.delayed {
+		timer.Stop()
+		d.enqueue(delayed.key, delayed.job)
+	}
+	d.delayed = make(map[*time.Timer]delayedJob)
+	d.closed = true
+	d.ready.Broadcast()
+	d.mutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		d.done.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		return ctx.Err()
+	}
+}
+
+// enqueue adds a job to the queue of its key. The caller must hold the mutex.
+func (d *Dispatcher) enqueue(key int64, job Job) {
+	queue, active := d.queues[key]
+	d.queues[key] = append(queue, job)
+	if !active {
+		d.keys = append(d.keys, key)
+		d.ready.Signal()
+	}
+}
+
+// work runs jobs until the dispatcher is shut down and no jobs are left
+func (d *Dispatcher) work() {
+	defer d.done.Done()
+
+	for {
+		d.mutex.Lock()
+		for len(d.keys) == 0 && !d.closed {
+			d.ready.Wait()
+		}
+		if len(d.keys) == 0 {
+			d.mutex.Unlock()
+			return
+		}
+
+		key := d.keys[0]
+		d.keys = d.keys[1:]
+		job := d.queues[key][0]
+		d.queues[key] = d.queues[key][1:]
+		d.mutex.Unlock()
+
+		d.run(key, job)
+
+		// Queue the key again if jobs were submitted for it in the meantime
+		d.mutex.Lock()
+		if len(d.queues[key]) == 0 {
+			delete(d.queues, key)
+		} else {
+			d.keys = append(d.keys, key)
+			d.ready.Signal()
+		}
+		d.mutex.Unlock()
+	}
+}
+
+// run runs a job with its own timeout, keeping a panic from taking down the worker
+// and the jobs queued behind it
+func (d *Dispatcher) run(key int64, job Job) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.timeout)
+	defer cancel()
+
+	defer func() {
+		if err := recover(); err != nil {
+			slog.ErrorContext(ctx, "Recovered from panic in job", "key", key, "panic", err)
+		}
+	}()
+
+	job(ctx)
+}
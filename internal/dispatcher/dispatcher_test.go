@@ -0,0 +1,202 @@
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// shutdown shuts a dispatcher down, failing the test if its jobs don't finish in time
+func shutdown(t *testing.T, d *Dispatcher) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+}
+
+// waitFor waits for a signal on a channel, failing the test if it doesn't come in time
+func waitFor(t *testing.T, signal <-chan struct{}, what string) {
+	t.Helper()
+
+	select {
+	case <-signal:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
+
+func TestJobsOfAKeyRunInOrder(t *testing.T) {
+	d := New(8, time.Second)
+
+	var mutex sync.Mutex
+	order := make(map[int64][]int)
+	for i := 0; i < 100; i++ {
+		for key := int64(1); key <= 5; key++ {
+			i, key := i, key
+			d.Submit(key, func(ctx context.Context) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				order[key] = append(order[key], i)
+			})
+		}
+	}
+	shutdown(t, d)
+
+	for key := int64(1); key <= 5; key++ {
+		if len(order[key]) != 100 {
+			t.Fatalf("key %d ran %d jobs, want 100", key, len(order[key]))
+		}
+		for i, job := range order[key] {
+			if job != i {
+				t.Fatalf("key %d ran job %d as number %d", key, job, i)
+			}
+		}
+	}
+}
+
+func TestKeysRunInParallel(t *testing.T) {
+	d := New(2, 5*time.Second)
+	defer shutdown(t, d)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 3)
+	blocking := func(ctx context.Context) {
+		started <- struct{}{}
+		<-release
+	}
+
+	d.Submit(1, blocking)
+	d.Submit(1, blocking)
+	d.Submit(2, blocking)
+
+	// Both keys get a worker while their first jobs block
+	waitFor(t, started, "the first job")
+	waitFor(t, started, "the second job")
+
+	// The second job of key 1 waits for the first although a worker would be free for it
+	select {
+	case <-started:
+		t.Fatal("two jobs of the same key ran at the same time")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	waitFor(t, started, "the queued job")
+}
+
+func TestSubmitAfter(t *testing.T) {
+	d := New(2, time.Second)
+	defer shutdown(t, d)
+
+	const delay = 50 * time.Millisecond
+	submitted := time.Now()
+	ran := make(chan struct{})
+	var ranAt time.Time
+	d.SubmitAfter(1, delay, func(ctx context.Context) {
+		ranAt = time.Now()
+		close(ran)
+	})
+
+	waitFor(t, ran, "the delayed job")
+	if elapsed := ranAt.Sub(submitted); elapsed < delay {
+		t.Errorf("delayed job ran after %v, want at least %v", elapsed, delay)
+	}
+}
+
+func TestSubmitAfterRunsBehindEarlierJobs(t *testing.T) {
+	d := New(2, 5*time.Second)
+	defer shutdown(t, d)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	var mutex sync.Mutex
+	var order []string
+	record := func(name string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		order = append(order, name)
+	}
+
+	d.Submit(1, func(ctx context.Context) {
+		<-release
+		record("first")
+	})
+	d.SubmitAfter(1, time.Millisecond, func(ctx context.Context) {
+		record("delayed")
+		close(done)
+	})
+
+	// The delay passes while the first job is still running
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	waitFor(t, done, "the delayed job")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(order) != 2 || order[0] != "first" || order[1] != "delayed" {
+		t.Errorf("jobs ran in order %v, want [first delayed]", order)
+	}
+}
+
+func TestShutdownRunsPendingJobs(t *testing.T) {
+	d := New(1, time.Second)
+
+	var mutex sync.Mutex
+	ran := 0
+	count := func(ctx context.Context) {
+		time.Sleep(time.Millisecond)
+		mutex.Lock()
+		defer mutex.Unlock()
+		ran++
+	}
+
+	for i := 0; i < 20; i++ {
+		d.Submit(int64(i%3), count)
+	}
+	// Delayed jobs are run right away rather than lost
+	d.SubmitAfter(1, time.Hour, count)
+
+	shutdown(t, d)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if ran != 21 {
+		t.Errorf("ran %d jobs before shutting down, want 21", ran)
+	}
+
+	if d.Submit(1, count) {
+		t.Error("Submit accepted a job after shutting down")
+	}
+}
+
+func TestShutdownGivesUpAtItsDeadline(t *testing.T) {
+	d := New(1, time.Hour)
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	d.Submit(1, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+	})
+	waitFor(t, started, "the job")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := d.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Shutdown took %v past its deadline", elapsed)
+	}
+
+	// The job still running is cancelled
+	waitFor(t, cancelled, "the job to be cancelled")
+}
@@ -0,0 +1,169 @@
+// Package logging sets up the bot's structured logs.
+//
+// Logs are JSON lines written with log/slog. A handler adds what it works on, such as the
+// update, user, chat, command and quiz session, to its context with With; every line logged
+// with that context through the *Context functions of slog carries those fields.
+//
+// Secrets and personal data are redacted before a line is written: attributes with keys such
+// as token, password, username or first_name are masked, and Telegram bot tokens and the
+// names in Telegram payloads are masked wherever they appear in a message or value, e.g. in
+// the request URLs of client errors.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces the values that are masked
+const Redacted = "[redacted]"
+
+// New creates a logger that writes JSON lines to w at the given level and above.
+// Levels are "debug", "info", "warn" and "error"; anything else means info.
+func New(w io.Writer, level string) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       ParseLevel(level),
+		ReplaceAttr: redact,
+	})
+	return slog.New(contextHandler{handler})
+}
+
+// ParseLevel returns the slog level of a configured log level
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// contextKey is the key of the fields stored in a context
+type contextKey struct{}
+
+// With returns a copy of ctx whose log lines carry the given fields, in addition to the ones
+// ctx carries already; a field that ctx carries already takes the new value.
+// Fields are key-value pairs as in slog.Logger.With.
+func With(ctx context.Context, args ...any) context.Context {
+	fields, _ := ctx.Value(contextKey{}).([]slog.Attr)
+
+	record := slog.Record{}
+	record.Add(args...)
+
+	combined := make([]slog.Attr, 0, len(fields)+record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		combined = append(combined, attr)
+		return true
+	})
+	for _, field := range fields {
+		if !hasKey(combined, field.Key) {
+			combined = append(combined, field)
+		}
+	}
+
+	return context.WithValue(ctx, contextKey{}, combined)
+}
+
+// hasKey reports whether attrs contain an attribute with the key
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHandler adds the fields stored in the context to each record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if fields, ok := ctx.Value(contextKey{}).([]slog.Attr); ok {
+		record.AddAttrs(fields...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// sensitiveKeys are the attribute keys whose values are always masked
+var sensitiveKeys = map[string]bool{
+	"token":         true,
+	"secret_token":  true,
+	"password":      true,
+	"database_url":  true,
+	"username":      true,
+	"first_name":    true,
+	"last_name":     true,
+	"phone_number":  true,
+	"authorization": true,
+}
+
+var (
+	// telegramToken matches bot tokens, also inside API URLs such as https://api.telegram.org/bot<token>/getMe
+	telegramToken = regexp.MustCompile(`\d{5,}:[A-Za-z0-9_-]{30,}`)
+	// personalFields matches the names and phone numbers in the JSON of Telegram payloads
+	personalFields = regexp.MustCompile(`"(username|first_name|last_name|phone_number)":"(?:[^"\\]|\\.)*"`)
+	// printedPersonalFields matches them in structs printed with %+v, as the Telegram client does in debug mode
+	printedPersonalFields = regexp.MustCompile(`\b(UserName|FirstName|LastName|PhoneNumber):\S*`)
+)
+
+// redact masks the sensitive attributes of a record. It is used as slog.HandlerOptions.ReplaceAttr.
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		if scrubbed := Scrub(attr.Value.String()); scrubbed != attr.Value.String() {
+			return slog.String(attr.Key, scrubbed)
+		}
+	case slog.KindAny:
+		// Errors and other values are logged as text, which may contain tokens as well
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, Scrub(err.Error()))
+		}
+	}
+
+	return attr
+}
+
+// Scrub masks the Telegram bot tokens and personal fields of Telegram payloads in a text
+func Scrub(text string) string {
+	text = telegramToken.ReplaceAllString(text, Redacted)
+	text = personalFields.ReplaceAllString(text, `"$1":"`+Redacted+`"`)
+	text = printedPersonalFields.ReplaceAllString(text, "$1:"+Redacted)
+	return text
+}
+
+// Printer logs the lines of libraries that log with Printf and Println, such as the Telegram client
+type Printer struct {
+	Logger *slog.Logger
+	Level  slog.Level
+}
+
+// Printf logs a formatted line
+func (p Printer) Printf(format string, v ...interface{}) {
+	p.Logger.Log(context.Background(), p.Level, strings.TrimSuffix(fmt.Sprintf(format, v...), "\n"))
+}
+
+// Println logs a line
+func (p Printer) Println(v ...interface{}) {
+	p.Logger.Log(context.Background(), p.Level, strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
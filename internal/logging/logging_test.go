@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+const testToken = "123456789:ABC-DEF1234ghIkl-zyx57W2v1u123ew11"
+
+// logLine logs one line and returns its fields
+func logLine(t *testing.T, log func(logger *slog.Logger)) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	log(New(&buf, "debug"))
+
+	var fields map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &fields); err != nil {
+		t.Fatalf("decoding %q: %v", buf.String(), err)
+	}
+	return fields
+}
+
+func TestContextFields(t *testing.T) {
+	ctx := With(context.Background(), "update_id", 7, "user_id", 42, "command", "/prepare")
+	ctx = With(ctx, "session_id", 3, "command", "/duel")
+
+	fields := logLine(t, func(logger *slog.Logger) {
+		logger.ErrorContext(ctx, "Error fetching question", "question_id", 9)
+	})
+
+	want := map[string]any{"update_id": 7.0, "user_id": 42.0, "command": "/duel", "session_id": 3.0, "question_id": 9.0}
+	for key, value := range want {
+		if fields[key] != value {
+			t.Errorf("got %s %v, want %v", key, fields[key], value)
+		}
+	}
+	if fields["level"] != "ERROR" || fields["msg"] != "Error fetching question" {
+		t.Errorf("got %v", fields)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	payload := `{"id":42,"first_name":"Ada","last_name":"Lovelace","username":"ada","is_bot":false}`
+	fields := logLine(t, func(logger *slog.Logger) {
+		logger.Info("Request to https://api.telegram.org/bot"+testToken+"/getMe",
+			"error", errors.New(`Post "https://api.telegram.org/bot`+testToken+`/sendMessage": timeout`),
+			"response", payload,
+			"username", "ada",
+			"user_id", 42,
+		)
+	})
+
+	line, _ := json.Marshal(fields)
+	for _, secret := range []string{"ABC-DEF1234", "Ada", "Lovelace", `"ada"`} {
+		if strings.Contains(string(line), secret) {
+			t.Errorf("log line contains %s: %s", secret, line)
+		}
+	}
+	if fields["user_id"] != 42.0 || !strings.Contains(fields["response"].(string), `"id":42`) {
+		t.Errorf("fields that aren't personal were redacted: %s", line)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "warn")
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	if strings.Contains(buf.String(), "hidden") || !strings.Contains(buf.String(), "shown") {
+		t.Errorf("got %q, want only the warning", buf.String())
+	}
+}
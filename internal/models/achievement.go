@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Achievement IDs
+const (
+	AchievementPerfectQuiz   = "perfect_quiz"   // first quiz with every answer correct
+	AchievementCentury       = "century"        // 100 quiz questions answered
+	AchievementWeekStreak    = "week_streak"    // 7-day daily challenge streak
+	AchievementAllCategories = "all_categories" // questions of every category of a language answered
+	AchievementFirstPartner  = "first_partner"  // first mock interview completed with a partner
+)
+
+// Events that can unlock achievements
+const (
+	EventQuizCompleted      = "quiz_completed"
+	EventDailyAnswered      = "daily_answered"
+	EventInterviewCompleted = "interview_completed" // the user confirmed a mock interview with a matched partner
+)
+
+// Achievement is a milestone a user can unlock
+type Achievement struct {
+	ID          string `json:"id"`
+	Emoji       string `json:"emoji"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// UnlockedAchievement is an achievement a user has unlocked
+type UnlockedAchievement struct {
+	Achievement
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// Achievements lists every achievement in the order they are shown
+var Achievements = []Achievement{
+	{ID: AchievementPerfectQuiz, Emoji: "💯", Title: "Flawless", Description: "Answer every question of a quiz correctly"},
+	{ID: AchievementCentury, Emoji: "🎯", Title: "Century", Description: "Answer 100 quiz questions"},
+	{ID: AchievementWeekStreak, Emoji: "🔥", Title: "On Fire", Description: "Keep a 7-day daily challenge streak"},
+	{ID: AchievementAllCategories, Emoji: "🧭", Title: "Well-Rounded", Description: "Answer questions from every category of a language"},
+	{ID: AchievementFirstPartner, Emoji: "🤝", Title: "Interview Buddy", Description: "Complete your first mock interview with a partner"},
+}
+
+// FindAchievement looks up an achievement by ID
+func FindAchievement(id string) (Achievement, bool) {
+	for _, achievement := range Achievements {
+		if achievement.ID == id {
+			return achievement, true
+		}
+	}
+	return Achievement{}, false
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+)
+
+// DailyStreakGraceDays is how many days in a row a user may miss the daily challenge
+// without losing their streak
+const DailyStreakGraceDays = 1
+
+// DailySubscriber is a user who gets the daily challenge of a language every day
+type DailySubscriber struct {
+	UserID   int64  `json:"user_id"`
+	Language string `json:"language"`
+}
+
+// DailyStreak is a user's run of daily challenge days
+type DailyStreak struct {
+	Current int `json:"current"` // days in the streak that is still alive, 0 if it was lost
+	Longest int `json:"longest"` // days in the longest streak ever
+}
+
+// ChallengeDay returns the day of the daily challenge at a point in time.
+// Challenge days follow UTC so every user gets the same question.
+func ChallengeDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// DailyQuestionIndex picks the daily question of a language among count candidates.
+// The choice only depends on the day and language, so it is the same for every user and restart.
+func DailyQuestionIndex(day time.Time, language string, count int) int {
+	if count <= 0 {
+		return 0
+	}
+	sum := sha256.Sum256([]byte(ChallengeDay(day).Format("2006-01-02") + ":" + language))
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(count))
+}
+
+// CalculateDailyStreak computes the streaks from the days a user answered a daily challenge,
+// given newest first without duplicates. Consecutive answers may be up to DailyStreakGraceDays
+// missed days apart, and the current streak is alive while the latest answer is.
+func CalculateDailyStreak(days []time.Time, today time.Time) DailyStreak {
+	var streak DailyStreak
+	if len(days) == 0 {
+		return streak
+	}
+
+	maxGap := 1 + DailyStreakGraceDays
+	run := 1
+	streak.Longest = 1
+	current := -1
+	for i := 1; i < len(days); i++ {
+		if daysBetween(days[i], days[i-1]) <= maxGap {
+			run++
+		} else {
+			if current < 0 {
+				current = run
+			}
+			run = 1
+		}
+		if run > streak.Longest {
+			streak.Longest = run
+		}
+	}
+	if current < 0 {
+		current = run
+	}
+
+	if daysBetween(days[0], ChallengeDay(today)) <= maxGap {
+		streak.Current = current
+	}
+	return streak
+}
+
+// daysBetween returns the number of calendar days from one challenge day to a later one
+func daysBetween(from, to time.Time) int {
+	return int(ChallengeDay(to).Sub(ChallengeDay(from)).Hours() / 24)
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateDailyStreak(t *testing.T) {
+	today := time.Date(2026, time.March, 10, 15, 0, 0, 0, time.UTC)
+	day := func(daysAgo int) time.Time {
+		return ChallengeDay(today).AddDate(0, 0, -daysAgo)
+	}
+	moscow := time.FixedZone("UTC+3", 3*60*60)
+
+	tests := []struct {
+		name  string
+		days  []time.Time
+		today time.Time
+		want  DailyStreak
+	}{
+		{
+			name: "no answers",
+			want: DailyStreak{},
+		},
+		{
+			name: "answered today",
+			days: []time.Time{day(0)},
+			want: DailyStreak{Current: 1, Longest: 1},
+		},
+		{
+			name: "three days in a row",
+			days: []time.Time{day(0), day(1), day(2)},
+			want: DailyStreak{Current: 3, Longest: 3},
+		},
+		{
+			name: "one missed day is forgiven",
+			days: []time.Time{day(0), day(2), day(3)},
+			want: DailyStreak{Current: 3, Longest: 3},
+		},
+		{
+			name: "two missed days break the streak",
+			days: []time.Time{day(0), day(3), day(4)},
+			want: DailyStreak{Current: 1, Longest: 2},
+		},
+		{
+			name: "streak alive after missing yesterday",
+			days: []time.Time{day(2), day(3)},
+			want: DailyStreak{Current: 2, Longest: 2},
+		},
+		{
+			name: "streak lost after missing two days",
+			days: []time.Time{day(3), day(4)},
+			want: DailyStreak{Current: 0, Longest: 2},
+		},
+		{
+			name: "longest streak in the past",
+			days: []time.Time{day(0), day(5), day(6), day(7), day(8)},
+			want: DailyStreak{Current: 1, Longest: 4},
+		},
+		{
+			// 01:00 on March 10 in UTC+3 is still March 9 in UTC, so March 7 is only one missed day ago
+			name:  "days follow UTC",
+			days:  []time.Time{time.Date(2026, time.March, 7, 0, 0, 0, 0, time.UTC)},
+			today: time.Date(2026, time.March, 10, 1, 0, 0, 0, moscow),
+			want:  DailyStreak{Current: 1, Longest: 1},
+		},
+		{
+			name:  "UTC day boundary passed",
+			days:  []time.Time{time.Date(2026, time.March, 7, 0, 0, 0, 0, time.UTC)},
+			today: time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC),
+			want:  DailyStreak{Current: 0, Longest: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := tt.today
+			if now.IsZero() {
+				now = today
+			}
+			if got := CalculateDailyStreak(tt.days, now); got != tt.want {
+				t.Errorf("CalculateDailyStreak = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
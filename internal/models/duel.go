@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Duel statuses
+const (
+	DuelStatusPending  = "pending"  // waiting for the opponent to accept
+	DuelStatusActive   = "active"   // both users are answering
+	DuelStatusFinished = "finished" // both users completed their sessions
+	DuelStatusDeclined = "declined" // the opponent declined the challenge
+)
+
+// QuizDuel is a challenge between two users to answer the same questions
+type QuizDuel struct {
+	ID           int        `json:"id"`
+	ChallengerID int64      `json:"challenger_id"`
+	OpponentID   *int64     `json:"opponent_id,omitempty"` // nil until someone accepts an invite link
+	Language     string     `json:"language"`
+	QuestionIDs  []int      `json:"question_ids"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// DuelAnswer is one participant's answer to a duel question
+type DuelAnswer struct {
+	UserID         int64 `json:"user_id"`
+	IsCorrect      bool  `json:"is_correct"`
+	TimeToAnswerMs *int  `json:"time_to_answer_ms,omitempty"`
+}
+
+// DuelResult is one participant's overall result in a duel
+type DuelResult struct {
+	UserID      int64 `json:"user_id"`
+	Correct     int   `json:"correct"`
+	Answered    int   `json:"answered"`
+	TotalTimeMs int64 `json:"total_time_ms"`
+}
+
+// Beats reports whether this result wins against another one.
+// More correct answers win; with equal scores the faster participant wins.
+func (r DuelResult) Beats(other DuelResult) bool {
+	if r.Correct != other.Correct {
+		return r.Correct > other.Correct
+	}
+	return r.TotalTimeMs < other.TotalTimeMs
+}
+
+// IsParticipant reports whether a user takes part in the duel
+func (d *QuizDuel) IsParticipant(userID int64) bool {
+	return d.ChallengerID == userID || (d.OpponentID != nil && *d.OpponentID == userID)
+}
+
+// OtherParticipant returns the ID of the other user in the duel, or 0 if there is none yet
+func (d *QuizDuel) OtherParticipant(userID int64) int64 {
+	if d.ChallengerID == userID {
+		if d.OpponentID == nil {
+			return 0
+		}
+		return *d.OpponentID
+	}
+	return d.ChallengerID
+}
@@ -0,0 +1,16 @@
+package models
+
+// MaxFamiliarity is the familiarity of a question a user knew on several reviews in a row
+const MaxFamiliarity = 5
+
+// NextFamiliarity returns a question's familiarity after a user rated a flashcard.
+// Knowing the answer raises it by one up to MaxFamiliarity; not knowing it starts over.
+func NextFamiliarity(current int, knew bool) int {
+	if !knew {
+		return 0
+	}
+	if current >= MaxFamiliarity {
+		return MaxFamiliarity
+	}
+	return current + 1
+}
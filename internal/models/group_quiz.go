@@ -0,0 +1,15 @@
+package models
+
+// GroupAnswer is one member's answer to a question of a group quiz
+type GroupAnswer struct {
+	UserID    int64 `json:"user_id"`
+	IsCorrect bool  `json:"is_correct"`
+}
+
+// GroupScore is one member's result in a group quiz
+type GroupScore struct {
+	UserID      int64 `json:"user_id"`
+	Correct     int   `json:"correct"`
+	Answered    int   `json:"answered"`
+	TotalTimeMs int64 `json:"total_time_ms"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// Leaderboard periods
+const (
+	LeaderboardWeekly  = "weekly"  // sessions completed in the last 7 days
+	LeaderboardAllTime = "alltime" // every completed session
+)
+
+// LeaderboardEntry is a user's rank-relevant totals on a leaderboard
+type LeaderboardEntry struct {
+	User          User `json:"user"`
+	Correct       int  `json:"correct"`
+	HintedAnswers int  `json:"hinted_answers"` // correct answers given after a hint
+	Questions     int  `json:"questions"`
+}
+
+// Accuracy returns the percentage of questions the user answered correctly.
+// Like in QuizSession.GetScore, answers given after a hint lose HintPenalty of their point.
+func (e LeaderboardEntry) Accuracy() float64 {
+	if e.Questions == 0 {
+		return 0
+	}
+	points := float64(e.Correct) - HintPenalty*float64(e.HintedAnswers)
+	return points / float64(e.Questions) * 100
+}
@@ -1,44 +1,342 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
 	"time"
 )
 
+// Question types
+const (
+	QuestionTypeSingle = "single" // exactly one correct option
+	QuestionTypeMulti  = "multi"  // one or more correct options, submitted together
+	QuestionTypeText   = "text"   // free-text answer graded by normalized match
+	QuestionTypeOutput = "output" // "what does this code print", graded on exact output
+)
+
+// Render modes of a quiz session
+const (
+	RenderModeKeyboard = "keyboard" // questions with inline keyboard buttons
+	RenderModePoll     = "poll"     // native Telegram quiz polls where the question allows it
+)
+
+// BookmarksLanguage is the session language of quizzes made of a user's bookmarked questions,
+// which may mix questions of several languages
+const BookmarksLanguage = "bookmarks"
+
+// HintPenalty is the share of a point lost by a correct answer given after a hint
+const HintPenalty = 0.5
+
+// QuestionTypes lists the known question types
+var QuestionTypes = []string{QuestionTypeSingle, QuestionTypeMulti, QuestionTypeText, QuestionTypeOutput}
+
+// Difficulties lists the known question difficulties, from easiest to hardest
+var Difficulties = []string{"beginner", "intermediate", "advanced"}
+
 // QuizQuestion represents a single quiz question
 type QuizQuestion struct {
-	ID            int       `json:"id"`
-	Language      string    `json:"language"`
-	Category      string    `json:"category"`
-	Difficulty    string    `json:"difficulty"`
-	QuestionText  string    `json:"question_text"`
-	AnswerOptions []string  `json:"answer_options"`
-	CorrectAnswer string    `json:"correct_answer"`
-	Explanation   string    `json:"explanation"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID                   int       `json:"id"`
+	Language             string    `json:"language"`
+	Category             string    `json:"category"`
+	Difficulty           string    `json:"difficulty"`
+	Type                 string    `json:"type"`
+	QuestionText         string    `json:"question_text"`
+	AnswerOptions        []string  `json:"answer_options"`
+	CorrectAnswer        string    `json:"correct_answer"`
+	CorrectOptionIndex   int       `json:"correct_option_index"`
+	CorrectOptionIndexes []int     `json:"correct_option_indexes,omitempty"` // multi-select questions
+	AcceptedAnswers      []string  `json:"accepted_answers,omitempty"`       // alternatives for free-text questions
+	Explanation          string    `json:"explanation"`
+	Hint                 string    `json:"hint,omitempty"` // shown by the Hint button, optional
+	Active               bool      `json:"active"`
+	CreatedAt            time.Time `json:"created_at"`
 }
 
 // QuizSession represents an active quiz session for a user
 type QuizSession struct {
-	ID                  int        `json:"id"`
-	UserID              int64      `json:"user_id"`
-	Language            string     `json:"language"`
-	CurrentQuestionIndex int       `json:"current_question_index"`
-	QuestionIDs         []int      `json:"question_ids"`
-	CorrectAnswers      int        `json:"correct_answers"`
-	StartedAt           time.Time  `json:"started_at"`
-	CompletedAt         *time.Time `json:"completed_at,omitempty"`
+	ID                   int        `json:"id"`
+	UserID               int64      `json:"user_id"`
+	Language             string     `json:"language"`
+	CurrentQuestionIndex int        `json:"current_question_index"`
+	QuestionIDs          []int      `json:"question_ids"`
+	OptionOrders         [][]int    `json:"option_orders,omitempty"`
+	CorrectAnswers       int        `json:"correct_answers"`
+	HintUsed             bool       `json:"hint_used"`      // a hint was shown for the current question
+	HintedAnswers        int        `json:"hinted_answers"` // correct answers given after a hint
+	Skips                int        `json:"skips"`
+	Timed                bool       `json:"timed"`
+	RenderMode           string     `json:"render_mode"`
+	DuelID               *int       `json:"duel_id,omitempty"`
+	ChatID               *int64     `json:"chat_id,omitempty"`
+	QuestionSentAt       *time.Time `json:"question_sent_at,omitempty"` // nil from an answer until the next question is shown
+	StartedAt            time.Time  `json:"started_at"`
+	CompletedAt          *time.Time `json:"completed_at,omitempty"`
+}
+
+// QuizOptions are the settings chosen when starting a quiz
+type QuizOptions struct {
+	Timed      bool   // each question has a countdown
+	RenderMode string // RenderModeKeyboard or RenderModePoll
+	DuelID     *int   // set for sessions taking part in a duel
+	ChatID     *int64 // set for sessions run in a group chat
+}
+
+// QuizPoll links a native quiz poll to the session question it was sent for
+type QuizPoll struct {
+	PollID        string    `json:"poll_id"`
+	SessionID     int       `json:"session_id"`
+	QuestionIndex int       `json:"question_index"`
+	ChatID        int64     `json:"chat_id"`
+	MessageID     int       `json:"message_id"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // QuizAnswer represents a user's answer to a quiz question
 type QuizAnswer struct {
-	ID         int       `json:"id"`
-	UserID     int64     `json:"user_id"`
-	SessionID  int       `json:"session_id"`
-	QuestionID int       `json:"question_id"`
-	AnswerGiven string    `json:"answer_given"`
-	IsCorrect  bool      `json:"is_correct"`
-	AnsweredAt time.Time `json:"answered_at"`
+	ID             int       `json:"id"`
+	UserID         int64     `json:"user_id"`
+	SessionID      int       `json:"session_id"`
+	QuestionID     int       `json:"question_id"`
+	AnswerIndex    *int      `json:"answer_index,omitempty"`
+	AnswerGiven    string    `json:"answer_given"`
+	IsCorrect      bool      `json:"is_correct"`
+	TimeToAnswerMs *int      `json:"time_to_answer_ms,omitempty"`
+	UsedHint       bool      `json:"used_hint"`
+	Skipped        bool      `json:"skipped"` // the question was skipped and comes back later in the session
+	AnsweredAt     time.Time `json:"answered_at"`
+}
+
+// TextHash identifies a question by its language and normalized text.
+// It is used to de-duplicate questions when importing question banks.
+func (q *QuizQuestion) TextHash() string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(q.QuestionText), " "))
+	sum := sha256.Sum256([]byte(q.Language + "\n" + normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate checks that a question is complete and answerable
+func (q *QuizQuestion) Validate() error {
+	var errs []error
+
+	if strings.TrimSpace(q.Language) == "" {
+		errs = append(errs, errors.New("language is empty"))
+	}
+	if strings.TrimSpace(q.Category) == "" {
+		errs = append(errs, errors.New("category is empty"))
+	}
+	if !contains(Difficulties, q.Difficulty) {
+		errs = append(errs, fmt.Errorf("unknown difficulty %q", q.Difficulty))
+	}
+	if strings.TrimSpace(q.QuestionText) == "" {
+		errs = append(errs, errors.New("question text is empty"))
+	}
+	if strings.TrimSpace(q.Explanation) == "" {
+		errs = append(errs, errors.New("explanation is empty"))
+	}
+
+	switch q.Type {
+	case QuestionTypeSingle:
+		if len(q.AnswerOptions) < 2 {
+			errs = append(errs, errors.New("needs at least two answer options"))
+		}
+		if q.CorrectOptionIndex < 0 || q.CorrectOptionIndex >= len(q.AnswerOptions) {
+			errs = append(errs, fmt.Errorf("correct answer %q is not one of the answer options", q.CorrectAnswer))
+		}
+	case QuestionTypeMulti:
+		if len(q.AnswerOptions) < 2 {
+			errs = append(errs, errors.New("needs at least two answer options"))
+		}
+		if len(q.CorrectOptionIndexes) == 0 {
+			errs = append(errs, errors.New("has no correct options"))
+		}
+		for _, i := range q.CorrectOptionIndexes {
+			if i < 0 || i >= len(q.AnswerOptions) {
+				errs = append(errs, fmt.Errorf("correct option %d is not one of the answer options", i))
+			}
+		}
+	case QuestionTypeText, QuestionTypeOutput:
+		if strings.TrimSpace(q.CorrectAnswer) == "" {
+			errs = append(errs, errors.New("correct answer is empty"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown question type %q", q.Type))
+	}
+
+	return errors.Join(errs...)
+}
+
+// contains reports whether a list of strings contains a value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// HasOptions returns true if the question is answered by choosing from its options
+func (q *QuizQuestion) HasOptions() bool {
+	return q.Type != QuestionTypeText && q.Type != QuestionTypeOutput
+}
+
+// HasHint reports whether the Hint button can help with the question:
+// it has a hint of its own or enough options to remove wrong ones
+func (q *QuizQuestion) HasHint() bool {
+	return q.Hint != "" || q.CanEliminateOptions()
+}
+
+// CanEliminateOptions reports whether a 50/50 hint can remove wrong options
+// and still leave a choice between at least two
+func (q *QuizQuestion) CanEliminateOptions() bool {
+	return q.Type == QuestionTypeSingle && len(q.AnswerOptions) >= 3
+}
+
+// EliminatedOptions returns the indexes of the wrong options removed by a 50/50 hint.
+// Two wrong options are removed, or fewer if that would leave only the correct one.
+// The choice only depends on seed, so the same options stay removed when the question is shown again.
+func (q *QuizQuestion) EliminatedOptions(seed int64) map[int]bool {
+	eliminated := make(map[int]bool)
+	if !q.CanEliminateOptions() {
+		return eliminated
+	}
+
+	var wrong []int
+	for i := range q.AnswerOptions {
+		if !q.IsCorrectOption(i) {
+			wrong = append(wrong, i)
+		}
+	}
+
+	count := 2
+	if len(q.AnswerOptions)-count < 2 {
+		count = len(q.AnswerOptions) - 2
+	}
+	if count > len(wrong) {
+		count = len(wrong)
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	random.Shuffle(len(wrong), func(i, j int) { wrong[i], wrong[j] = wrong[j], wrong[i] })
+	for _, i := range wrong[:count] {
+		eliminated[i] = true
+	}
+	return eliminated
+}
+
+// CorrectAnswerText returns the correct answer as it should be shown to the user
+func (q *QuizQuestion) CorrectAnswerText() string {
+	switch q.Type {
+	case QuestionTypeMulti:
+		var options []string
+		for _, i := range q.CorrectOptionIndexes {
+			if i >= 0 && i < len(q.AnswerOptions) {
+				options = append(options, q.AnswerOptions[i])
+			}
+		}
+		return strings.Join(options, ", ")
+	case QuestionTypeText, QuestionTypeOutput:
+		return q.CorrectAnswer
+	default:
+		return q.CorrectOption()
+	}
+}
+
+// CorrectOption returns the text of the correct answer option
+func (q *QuizQuestion) CorrectOption() string {
+	if q.CorrectOptionIndex < 0 || q.CorrectOptionIndex >= len(q.AnswerOptions) {
+		return q.CorrectAnswer
+	}
+	return q.AnswerOptions[q.CorrectOptionIndex]
+}
+
+// IsCorrectOption returns true if the option at the given index is the correct answer
+func (q *QuizQuestion) IsCorrectOption(optionIndex int) bool {
+	return optionIndex == q.CorrectOptionIndex && optionIndex < len(q.AnswerOptions)
+}
+
+// IsCorrectSelection returns true if the selected option indexes are exactly the correct ones
+func (q *QuizQuestion) IsCorrectSelection(optionIndexes []int) bool {
+	if len(optionIndexes) != len(q.CorrectOptionIndexes) {
+		return false
+	}
+
+	selected := append([]int(nil), optionIndexes...)
+	correct := append([]int(nil), q.CorrectOptionIndexes...)
+	sort.Ints(selected)
+	sort.Ints(correct)
+
+	for i := range selected {
+		if selected[i] != correct[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCorrectText grades a typed answer.
+// Output questions require the exact output, ignoring trailing whitespace and line endings.
+// Free-text questions accept the correct answer or any accepted answer, ignoring case,
+// extra whitespace and trailing punctuation.
+func (q *QuizQuestion) IsCorrectText(answer string) bool {
+	if q.Type == QuestionTypeOutput {
+		return NormalizeOutput(answer) == NormalizeOutput(q.CorrectAnswer)
+	}
+
+	normalized := NormalizeTextAnswer(answer)
+	if normalized == "" {
+		return false
+	}
+
+	for _, accepted := range append([]string{q.CorrectAnswer}, q.AcceptedAnswers...) {
+		if normalized == NormalizeTextAnswer(accepted) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTextAnswer lowercases a free-text answer, collapses whitespace and
+// strips surrounding quotes and trailing punctuation
+func NormalizeTextAnswer(answer string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(answer), " "))
+	normalized = strings.Trim(normalized, "\"'`")
+	return strings.TrimRight(normalized, ".!?;")
+}
+
+// NormalizeOutput unifies line endings and drops trailing whitespace of program output
+func NormalizeOutput(output string) string {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// ShuffledOptionOrder returns a random permutation of the option indexes of a question
+func ShuffledOptionOrder(optionCount int) []int {
+	return rand.Perm(optionCount)
+}
+
+// OptionOrder returns the order in which the options of the current question are shown.
+// Element i is the index in AnswerOptions of the option displayed at position i.
+// Sessions created before options were shuffled use the original order.
+func (s *QuizSession) OptionOrder(optionCount int) []int {
+	if s.CurrentQuestionIndex < len(s.OptionOrders) && len(s.OptionOrders[s.CurrentQuestionIndex]) == optionCount {
+		return s.OptionOrders[s.CurrentQuestionIndex]
+	}
+
+	order := make([]int, optionCount)
+	for i := range order {
+		order[i] = i
+	}
+	return order
 }
 
 // IsComplete returns true if the quiz session is complete
@@ -46,12 +344,47 @@ func (s *QuizSession) IsComplete() bool {
 	return s.CurrentQuestionIndex >= len(s.QuestionIDs) || s.CompletedAt != nil
 }
 
+// IsGroup reports whether the session is run in a group chat, where any member can answer
+func (s *QuizSession) IsGroup() bool {
+	return s.ChatID != nil
+}
+
+// TimeToAnswer returns how long the current question has been on screen.
+// It returns nil when the time the question was sent is unknown.
+func (s *QuizSession) TimeToAnswer(now time.Time) *time.Duration {
+	if s.QuestionSentAt == nil {
+		return nil
+	}
+	elapsed := now.Sub(*s.QuestionSentAt)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return &elapsed
+}
+
 // GetScore returns the score as a percentage
 func (s *QuizSession) GetScore() float64 {
 	if s.CurrentQuestionIndex == 0 {
 		return 0
 	}
-	return float64(s.CorrectAnswers) / float64(s.CurrentQuestionIndex) * 100
+	points := float64(s.CorrectAnswers) - HintPenalty*float64(s.HintedAnswers)
+	return points / float64(s.CurrentQuestionIndex) * 100
+}
+
+// MoveCurrentQuestionToEnd moves the current question and its option order to the end of the session
+func (s *QuizSession) MoveCurrentQuestionToEnd() {
+	i := s.CurrentQuestionIndex
+	if i >= len(s.QuestionIDs) {
+		return
+	}
+
+	questionID := s.QuestionIDs[i]
+	s.QuestionIDs = append(append(s.QuestionIDs[:i:i], s.QuestionIDs[i+1:]...), questionID)
+
+	if i < len(s.OptionOrders) {
+		order := s.OptionOrders[i]
+		s.OptionOrders = append(append(s.OptionOrders[:i:i], s.OptionOrders[i+1:]...), order)
+	}
 }
 
 // ToJSON converts the QuestionIDs to a JSON string
@@ -66,4 +399,4 @@ func (s *QuizSession) ToJSON() (string, error) {
 // FromJSON populates QuestionIDs from a JSON string
 func (s *QuizSession) FromJSON(jsonStr string) error {
 	return json.Unmarshal([]byte(jsonStr), &s.QuestionIDs)
-}
\ No newline at end of file
+}
@@ -0,0 +1,34 @@
+package models
+
+import "testing"
+
+func TestTextHash(t *testing.T) {
+	// sha256 of "go\nwhat is a goroutine?", which quiz_question_text_hash in
+	// schema/000005_add_question_text_hash.up.sql must produce for the same texts
+	const want = "bd67aa270eb42e35794d5b1ceda540f632b1848442e855802073cebff453910c"
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "normalized", text: "what is a goroutine?"},
+		{name: "upper case", text: "What is a Goroutine?"},
+		{name: "repeated spaces", text: "What  is a\tgoroutine?"},
+		{name: "leading and trailing spaces", text: "  What is a goroutine?  "},
+		{name: "leading and trailing newlines", text: "\n\tWhat is a goroutine?\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := QuizQuestion{Language: "go", QuestionText: tt.text}
+			if got := q.TextHash(); got != want {
+				t.Errorf("TextHash() = %s, want %s", got, want)
+			}
+		})
+	}
+
+	other := QuizQuestion{Language: "python", QuestionText: "What is a goroutine?"}
+	if other.TextHash() == want {
+		t.Error("TextHash() is the same for different languages")
+	}
+}
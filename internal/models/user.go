@@ -8,6 +8,7 @@ type User struct {
 	LastName  string // Telegram last name (may be empty)
 	Field     string // Selected field of interest (e.g., "backend", "frontend")
 	Level     string // Selected experience level (e.g., "intern", "junior", "middle", "senior")
+	Inactive  bool   // Messages to the user fail, they blocked the bot
 }
 
 // DisplayName returns the best available name for the user
@@ -17,10 +18,10 @@ func (u *User) DisplayName() string {
 	if u.Username != "" {
 		return "@" + u.Username
 	}
-	
+
 	if u.LastName != "" {
 		return u.FirstName + " " + u.LastName
 	}
-	
+
 	return u.FirstName
 }
@@ -0,0 +1,83 @@
+// Package monitoring exposes the bot's health and Prometheus metrics.
+//
+// The metrics below are registered in Registry, which NewServer serves on /metrics together
+// with the Go runtime and process metrics. The bot updates them where things happen: the
+// update and command metrics when it handles an update, the quiz metrics when quizzes start
+// and end. Database queries are timed by opening the database with the PostgresDriver of
+// this package, and Telegram API errors are counted by wrapping the API with InstrumentAPI.
+package monitoring
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// namespace prefixes the names of the bot's metrics
+const namespace = "interview_bot"
+
+// Registry holds the metrics served on /metrics
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var factory = promauto.With(Registry)
+
+var (
+	// Updates counts the updates received by type: message, command, callback_query, poll_answer or other
+	Updates = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_total",
+		Help:      "Updates received from Telegram by type.",
+	}, []string{"type"})
+
+	// CommandDuration measures how long handling an update takes, by the command or button pressed
+	CommandDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time taken to handle an update by command or button action.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command"})
+
+	// QueryDuration measures database queries by operation: query or exec
+	QueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Time taken by database queries by operation.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+
+	// QuizzesStarted counts the quizzes started by kind: solo, duel or group
+	QuizzesStarted = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quizzes_started_total",
+		Help:      "Quizzes started by kind.",
+	}, []string{"kind"})
+
+	// QuizzesCompleted counts the quizzes completed by kind: solo, duel or group
+	QuizzesCompleted = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "quizzes_completed_total",
+		Help:      "Quizzes completed by kind.",
+	}, []string{"kind"})
+
+	// MatchProposals counts the interview partners proposed to users
+	MatchProposals = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "match_proposals_total",
+		Help:      "Interview partner matches proposed.",
+	})
+
+	// TelegramErrors counts the failed Telegram API requests by request and error code,
+	// "network" for requests that got no answer
+	TelegramErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_api_errors_total",
+		Help:      "Failed Telegram API requests by request and error code.",
+	}, []string{"request", "code"})
+)
@@ -0,0 +1,98 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/logging"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// checkTimeout is how long a readiness check may take
+const checkTimeout = 5 * time.Second
+
+// Check is a dependency the bot needs to be ready, such as the database
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// NewServer creates a server listening on addr with these endpoints:
+//
+//	/healthz  the process is up
+//	/readyz   every check passes; otherwise 503 with the failed checks
+//	/metrics  the metrics in Registry, in the Prometheus format
+func NewServer(addr string, checks ...Check) *http.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		defer cancel()
+
+		if failures := runChecks(ctx, checks); len(failures) > 0 {
+			http.Error(w, strings.Join(failures, "\n"), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// runChecks runs the checks at the same time and describes the ones that failed or didn't finish in time.
+// Errors are scrubbed, since those of the Telegram client contain the bot token.
+func runChecks(ctx context.Context, checks []Check) []string {
+	type result struct {
+		index int
+		err   error
+	}
+	results := make(chan result, len(checks))
+	for i, check := range checks {
+		i, check := i, check
+		go func() {
+			results <- result{i, check.Run(ctx)}
+		}()
+	}
+
+	failures := make([]string, len(checks))
+	for i, check := range checks {
+		failures[i] = check.Name + ": timed out"
+	}
+	for range checks {
+		select {
+		case r := <-results:
+			failures[r.index] = ""
+			if r.err != nil {
+				failures[r.index] = checks[r.index].Name + ": " + logging.Scrub(r.err.Error())
+			}
+		case <-ctx.Done():
+			// Checks that ignore the context keep their timed out failure
+			return nonEmpty(failures)
+		}
+	}
+	return nonEmpty(failures)
+}
+
+// nonEmpty returns the strings that aren't empty
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
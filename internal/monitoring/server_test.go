@@ -0,0 +1,133 @@
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// get requests a path from the server's handler and returns the status code and body
+func get(t *testing.T, server *http.Server, path string) (int, string) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	body, _ := io.ReadAll(recorder.Result().Body)
+	return recorder.Code, string(body)
+}
+
+func TestHealthAndReadiness(t *testing.T) {
+	database := errors.New("connection refused")
+	server := NewServer(":0",
+		Check{Name: "database", Run: func(ctx context.Context) error { return database }},
+		Check{Name: "telegram", Run: func(ctx context.Context) error {
+			return errors.New(`Post "https://api.telegram.org/bot123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11/getMe": EOF`)
+		}},
+	)
+
+	if code, _ := get(t, server, "/healthz"); code != http.StatusOK {
+		t.Errorf("/healthz returned %d, want 200", code)
+	}
+
+	code, body := get(t, server, "/readyz")
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz returned %d with failing checks, want 503", code)
+	}
+	if !strings.Contains(body, "database: connection refused") || !strings.Contains(body, "telegram: ") {
+		t.Errorf("/readyz doesn't report both failed checks: %q", body)
+	}
+	if strings.Contains(body, "ABC-DEF1234") {
+		t.Errorf("/readyz shows the bot token: %q", body)
+	}
+
+	database = nil
+	server = NewServer(":0", Check{Name: "database", Run: func(ctx context.Context) error { return database }})
+	if code, _ := get(t, server, "/readyz"); code != http.StatusOK {
+		t.Errorf("/readyz returned %d with passing checks, want 200", code)
+	}
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	blocked := make(chan struct{})
+	defer close(blocked)
+
+	failures := runChecks(contextWithTimeout(t, 50*time.Millisecond), []Check{
+		{Name: "fast", Run: func(ctx context.Context) error { return nil }},
+		{Name: "stuck", Run: func(ctx context.Context) error { <-blocked; return nil }},
+	})
+
+	if len(failures) != 1 || failures[0] != "stuck: timed out" {
+		t.Errorf("got failures %q, want the stuck check timed out", failures)
+	}
+}
+
+// contextWithTimeout returns a context that times out and is cancelled when the test ends
+func contextWithTimeout(t *testing.T, timeout time.Duration) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestMetrics(t *testing.T) {
+	Updates.WithLabelValues("command").Inc()
+	MatchProposals.Inc()
+
+	code, body := get(t, NewServer(":0"), "/metrics")
+	if code != http.StatusOK {
+		t.Fatalf("/metrics returned %d", code)
+	}
+	for _, metric := range []string{`interview_bot_updates_total{type="command"}`, "interview_bot_match_proposals_total", "go_goroutines"} {
+		if !strings.Contains(body, metric) {
+			t.Errorf("/metrics doesn't contain %s", metric)
+		}
+	}
+}
+
+// failingAPI fails every request with err
+type failingAPI struct {
+	err error
+}
+
+func (a failingAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return tgbotapi.Message{}, a.err
+}
+
+func (a failingAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return nil, a.err
+}
+
+func (a failingAPI) GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error) {
+	return tgbotapi.ChatMember{}, a.err
+}
+
+func (a failingAPI) GetChatMembersCount(config tgbotapi.ChatMemberCountConfig) (int, error) {
+	return 0, a.err
+}
+
+func TestTelegramErrors(t *testing.T) {
+	// The counters are global, so only what this run adds is checked
+	blockedBefore := testutil.ToFloat64(TelegramErrors.WithLabelValues("Message", "403"))
+	offlineBefore := testutil.ToFloat64(TelegramErrors.WithLabelValues("Callback", "network"))
+
+	blocked := InstrumentAPI(failingAPI{&tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}})
+	blocked.Send(tgbotapi.NewMessage(1, "hello"))
+	blocked.Send(tgbotapi.NewMessage(1, "hello"))
+
+	offline := InstrumentAPI(failingAPI{errors.New("dial tcp: connection refused")})
+	offline.Request(tgbotapi.NewCallback("1", ""))
+
+	if got := testutil.ToFloat64(TelegramErrors.WithLabelValues("Message", "403")) - blockedBefore; got != 2 {
+		t.Errorf("got %v blocked messages, want 2", got)
+	}
+	if got := testutil.ToFloat64(TelegramErrors.WithLabelValues("Callback", "network")) - offlineBefore; got != 1 {
+		t.Errorf("got %v failed callback answers, want 1", got)
+	}
+}
@@ -0,0 +1,91 @@
+package monitoring
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amiosamu/interview-match-bot/internal/sender"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// InstrumentAPI returns an API that counts the failed requests made through api in TelegramErrors.
+// Requests the sender retries are counted on every attempt.
+func InstrumentAPI(api sender.API) sender.API {
+	return instrumentedAPI{api}
+}
+
+type instrumentedAPI struct {
+	api sender.API
+}
+
+func (a instrumentedAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	message, err := a.api.Send(c)
+	countError(RequestName(c), err)
+	return message, err
+}
+
+func (a instrumentedAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	response, err := a.api.Request(c)
+	countError(RequestName(c), err)
+	return response, err
+}
+
+func (a instrumentedAPI) GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error) {
+	member, err := a.api.GetChatMember(config)
+	countError("GetChatMember", err)
+	return member, err
+}
+
+func (a instrumentedAPI) GetChatMembersCount(config tgbotapi.ChatMemberCountConfig) (int, error) {
+	count, err := a.api.GetChatMembersCount(config)
+	countError("GetChatMembersCount", err)
+	return count, err
+}
+
+// RequestName names a request after its config type, e.g. EditMessageText for EditMessageTextConfig
+func RequestName(c tgbotapi.Chattable) string {
+	name := fmt.Sprintf("%T", c)
+	name = name[strings.LastIndex(name, ".")+1:]
+	return strings.TrimSuffix(name, "Config")
+}
+
+// countError counts a failed request
+func countError(request string, err error) {
+	if err == nil {
+		return
+	}
+
+	code := "network"
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		code = strconv.Itoa(apiErr.Code)
+	}
+	TelegramErrors.WithLabelValues(request, code).Inc()
+}
+
+// RegisterSenderStats exposes the counts of a sender as interview_bot_messages_total by result:
+// sent, retried, dropped, failed or blocked
+func RegisterSenderStats(s *sender.Sender) {
+	results := map[string]func(sender.Stats) int64{
+		"sent":    func(stats sender.Stats) int64 { return stats.Sent },
+		"retried": func(stats sender.Stats) int64 { return stats.Retried },
+		"dropped": func(stats sender.Stats) int64 { return stats.Dropped },
+		"failed":  func(stats sender.Stats) int64 { return stats.Failed },
+		"blocked": func(stats sender.Stats) int64 { return stats.Blocked },
+	}
+
+	for result, count := range results {
+		count := count
+		Registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        "messages_total",
+			Help:        "Messages sent through the rate limiter by result.",
+			ConstLabels: prometheus.Labels{"result": result},
+		}, func() float64 {
+			return float64(count(s.Stats()))
+		}))
+	}
+}
@@ -0,0 +1,187 @@
+package quizbank
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a question bank file format
+type Format string
+
+// Supported question bank formats
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
+
+// csvHeader lists the CSV columns. List columns hold JSON arrays,
+// since options frequently contain commas.
+var csvHeader = []string{
+	"language", "category", "difficulty", "type", "question",
+	"options", "correct_answer", "correct_answers", "accepted_answers", "explanation", "hint",
+}
+
+// ParseFormat returns the format with the given name
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "json":
+		return FormatJSON, nil
+	case "csv":
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("unknown format %q", name)
+	}
+}
+
+// FormatFromPath guesses the format of a file from its extension
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// Read decodes a question bank
+func Read(r io.Reader, format Format) ([]Question, error) {
+	var questions []Question
+
+	switch format {
+	case FormatYAML:
+		if err := yaml.NewDecoder(r).Decode(&questions); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("error decoding YAML: %w", err)
+		}
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&questions); err != nil {
+			return nil, fmt.Errorf("error decoding JSON: %w", err)
+		}
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	return questions, nil
+}
+
+// Write encodes a question bank
+func Write(w io.Writer, format Format, questions []Question) error {
+	switch format {
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(questions); err != nil {
+			return fmt.Errorf("error encoding YAML: %w", err)
+		}
+		return encoder.Close()
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(questions); err != nil {
+			return fmt.Errorf("error encoding JSON: %w", err)
+		}
+		return nil
+	case FormatCSV:
+		return writeCSV(w, questions)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// readCSV decodes a CSV question bank. Columns are matched by the header row,
+// so they may appear in any order and optional columns may be left out.
+func readCSV(r io.Reader) ([]Question, error) {
+	reader := csv.NewReader(r)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error decoding CSV: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+
+	var questions []Question
+	for line, record := range records[1:] {
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		q := Question{
+			Language:      field("language"),
+			Category:      field("category"),
+			Difficulty:    field("difficulty"),
+			Type:          field("type"),
+			Question:      field("question"),
+			CorrectAnswer: field("correct_answer"),
+			Explanation:   field("explanation"),
+			Hint:          field("hint"),
+		}
+
+		for name, list := range map[string]*[]string{
+			"options":          &q.Options,
+			"correct_answers":  &q.CorrectAnswers,
+			"accepted_answers": &q.AcceptedAnswers,
+		} {
+			value := strings.TrimSpace(field(name))
+			if value == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(value), list); err != nil {
+				// Header is line 1
+				return nil, fmt.Errorf("line %d: column %s must be a JSON array: %w", line+2, name, err)
+			}
+		}
+
+		questions = append(questions, q)
+	}
+
+	return questions, nil
+}
+
+// writeCSV encodes a CSV question bank
+func writeCSV(w io.Writer, questions []Question) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("error encoding CSV: %w", err)
+	}
+
+	for _, q := range questions {
+		lists := make([]string, 3)
+		for i, list := range [][]string{q.Options, q.CorrectAnswers, q.AcceptedAnswers} {
+			if len(list) == 0 {
+				continue
+			}
+			data, err := json.Marshal(list)
+			if err != nil {
+				return fmt.Errorf("error encoding CSV: %w", err)
+			}
+			lists[i] = string(data)
+		}
+
+		record := []string{
+			q.Language, q.Category, q.Difficulty, q.Type, q.Question,
+			lists[0], q.CorrectAnswer, lists[1], lists[2], q.Explanation, q.Hint,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error encoding CSV: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
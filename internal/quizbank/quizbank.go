@@ -0,0 +1,113 @@
+// Package quizbank converts quiz questions to and from question bank files
+// in YAML, JSON and CSV, the formats used to author questions outside the database.
+package quizbank
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// Question is a quiz question as written in a question bank file.
+// Correct answers are given as option text rather than indexes, which is
+// easier to author and review than the database representation.
+type Question struct {
+	Language        string   `json:"language" yaml:"language"`
+	Category        string   `json:"category" yaml:"category"`
+	Difficulty      string   `json:"difficulty" yaml:"difficulty"`
+	Type            string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Question        string   `json:"question" yaml:"question"`
+	Options         []string `json:"options,omitempty" yaml:"options,omitempty"`
+	CorrectAnswer   string   `json:"correct_answer,omitempty" yaml:"correct_answer,omitempty"`     // single, text and output questions
+	CorrectAnswers  []string `json:"correct_answers,omitempty" yaml:"correct_answers,omitempty"`   // multi-select questions
+	AcceptedAnswers []string `json:"accepted_answers,omitempty" yaml:"accepted_answers,omitempty"` // alternatives for text questions
+	Explanation     string   `json:"explanation" yaml:"explanation"`
+	Hint            string   `json:"hint,omitempty" yaml:"hint,omitempty"` // shown by the Hint button instead of removing options
+}
+
+// ToModel converts a bank question into a validated quiz question.
+// Questions without a type are single-choice. All problems found are reported together.
+func (q *Question) ToModel() (*models.QuizQuestion, error) {
+	question := &models.QuizQuestion{
+		Language:           q.Language,
+		Category:           q.Category,
+		Difficulty:         q.Difficulty,
+		Type:               q.Type,
+		QuestionText:       q.Question,
+		AnswerOptions:      q.Options,
+		CorrectAnswer:      q.CorrectAnswer,
+		CorrectOptionIndex: -1,
+		AcceptedAnswers:    q.AcceptedAnswers,
+		Explanation:        q.Explanation,
+		Hint:               q.Hint,
+		Active:             true,
+	}
+
+	if question.Type == "" {
+		question.Type = models.QuestionTypeSingle
+	}
+	if question.AnswerOptions == nil {
+		question.AnswerOptions = []string{}
+	}
+
+	var errs []error
+	switch question.Type {
+	case models.QuestionTypeSingle:
+		// Validate reports a correct answer missing from the options
+		question.CorrectOptionIndex = optionIndex(q.Options, q.CorrectAnswer)
+	case models.QuestionTypeMulti:
+		for _, answer := range q.CorrectAnswers {
+			i := optionIndex(q.Options, answer)
+			if i < 0 {
+				errs = append(errs, fmt.Errorf("correct answer %q is not one of the answer options", answer))
+				continue
+			}
+			question.CorrectOptionIndexes = append(question.CorrectOptionIndexes, i)
+		}
+	}
+
+	if err := errors.Join(append(errs, question.Validate())...); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// FromModel converts a quiz question into its question bank form
+func FromModel(question *models.QuizQuestion) Question {
+	q := Question{
+		Language:        question.Language,
+		Category:        question.Category,
+		Difficulty:      question.Difficulty,
+		Type:            question.Type,
+		Question:        question.QuestionText,
+		Options:         question.AnswerOptions,
+		AcceptedAnswers: question.AcceptedAnswers,
+		Explanation:     question.Explanation,
+		Hint:            question.Hint,
+	}
+
+	switch question.Type {
+	case models.QuestionTypeMulti:
+		for _, i := range question.CorrectOptionIndexes {
+			if i >= 0 && i < len(question.AnswerOptions) {
+				q.CorrectAnswers = append(q.CorrectAnswers, question.AnswerOptions[i])
+			}
+		}
+	default:
+		q.CorrectAnswer = question.CorrectAnswerText()
+	}
+
+	return q
+}
+
+// optionIndex returns the index of an answer among the options, or -1
+func optionIndex(options []string, answer string) int {
+	for i, option := range options {
+		if option == answer {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,138 @@
+package quizbank
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// testQuestions has a question of every type, with text that needs quoting in every format
+func testQuestions() []*models.QuizQuestion {
+	return []*models.QuizQuestion{
+		{
+			Language:           "go",
+			Category:           "Syntax",
+			Difficulty:         "beginner",
+			Type:               models.QuestionTypeSingle,
+			QuestionText:       "Which declaration is valid, given `x` isn't declared yet?",
+			AnswerOptions:      []string{"var x int = 10", "let x = 10", "x := 10, y := 20"},
+			CorrectAnswer:      "var x int = 10",
+			CorrectOptionIndex: 0,
+			Explanation:        "Go has no `let`; \"x := 10, y := 20\" is two statements.",
+			Hint:               "Think of the keywords Go has.",
+			Active:             true,
+		},
+		{
+			Language:             "go",
+			Category:             "Types",
+			Difficulty:           "intermediate",
+			Type:                 models.QuestionTypeMulti,
+			QuestionText:         "Which types are comparable?",
+			AnswerOptions:        []string{"int", "map[string]int", "struct{ a, b int }", "[]byte"},
+			CorrectOptionIndex:   -1,
+			CorrectOptionIndexes: []int{0, 2},
+			Explanation:          "Maps and slices can only be compared to nil.",
+			Active:               true,
+		},
+		{
+			Language:           "go",
+			Category:           "Concurrency",
+			Difficulty:         "advanced",
+			Type:               models.QuestionTypeText,
+			QuestionText:       "Which keyword starts a goroutine?",
+			AnswerOptions:      []string{},
+			CorrectAnswer:      "go",
+			CorrectOptionIndex: -1,
+			AcceptedAnswers:    []string{"go statement", "the go keyword"},
+			Explanation:        "A go statement runs a function call in a new goroutine.",
+			Active:             true,
+		},
+		{
+			Language:           "go",
+			Category:           "Basics",
+			Difficulty:         "beginner",
+			Type:               models.QuestionTypeOutput,
+			QuestionText:       "What does this print?\n```\nfmt.Println(len(\"héllo\"), \"a,b\")\n```",
+			AnswerOptions:      []string{},
+			CorrectAnswer:      "6 a,b",
+			CorrectOptionIndex: -1,
+			Explanation:        "len counts bytes, and é takes two.",
+			Active:             true,
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatYAML, FormatJSON, FormatCSV} {
+		t.Run(string(format), func(t *testing.T) {
+			want := testQuestions()
+
+			var bank []Question
+			for _, question := range want {
+				bank = append(bank, FromModel(question))
+			}
+
+			var buffer bytes.Buffer
+			if err := Write(&buffer, format, bank); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+
+			read, err := Read(&buffer, format)
+			if err != nil {
+				t.Fatalf("Read: %v", err)
+			}
+			if len(read) != len(want) {
+				t.Fatalf("read %d questions, want %d", len(read), len(want))
+			}
+
+			for i := range read {
+				got, err := read[i].ToModel()
+				if err != nil {
+					t.Errorf("question %d: ToModel: %v", i+1, err)
+					continue
+				}
+				if !reflect.DeepEqual(got, want[i]) {
+					t.Errorf("question %d changed in a round trip:\ngot  %+v\nwant %+v", i+1, got, want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestToModelErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		question Question
+	}{
+		{
+			name: "single answer not an option",
+			question: Question{Language: "go", Category: "Syntax", Difficulty: "beginner", Question: "Which?",
+				Options: []string{"a", "b"}, CorrectAnswer: "c", Explanation: "Because."},
+		},
+		{
+			name: "multi answer not an option",
+			question: Question{Language: "go", Category: "Syntax", Difficulty: "beginner", Type: models.QuestionTypeMulti,
+				Question: "Which?", Options: []string{"a", "b"}, CorrectAnswers: []string{"a", "c"}, Explanation: "Because."},
+		},
+		{
+			name: "text without an answer",
+			question: Question{Language: "go", Category: "Syntax", Difficulty: "beginner", Type: models.QuestionTypeText,
+				Question: "Which?", Explanation: "Because."},
+		},
+		{
+			name: "unknown difficulty",
+			question: Question{Language: "go", Category: "Syntax", Difficulty: "expert", Question: "Which?",
+				Options: []string{"a", "b"}, CorrectAnswer: "a", Explanation: "Because."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if question, err := tt.question.ToModel(); err == nil {
+				t.Errorf("ToModel = %+v, want an error", question)
+			}
+		})
+	}
+}
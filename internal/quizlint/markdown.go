@@ -0,0 +1,69 @@
+package quizlint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// markdownEntities are the entity delimiters of Telegram's legacy Markdown parse mode
+var markdownEntities = map[string]string{
+	"```": "pre block",
+	"`":   "code span",
+	"*":   "bold text",
+	"_":   "italic text",
+}
+
+// CheckMarkdown reports text that Telegram's legacy Markdown parse mode would reject.
+// Entities can't be nested there, so inside an entity only its own delimiter matters.
+func CheckMarkdown(text string) error {
+	open := "" // delimiter of the entity we're in
+	start := 0 // position where it was opened
+
+	for position := 0; position < len(text); {
+		rest := text[position:]
+
+		if open != "" {
+			if strings.HasPrefix(rest, open) {
+				position += len(open)
+				open = ""
+			} else {
+				position++
+			}
+			continue
+		}
+
+		// A backslash escapes entity characters outside of entities
+		if len(rest) > 1 && rest[0] == '\\' && strings.ContainsRune("_*`[", rune(rest[1])) {
+			position += 2
+			continue
+		}
+
+		// Link text must be closed, the URL part is optional
+		if rest[0] == '[' {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return fmt.Errorf("unclosed link text starting at byte %d", position)
+			}
+			position += end + 1
+			continue
+		}
+
+		opened := false
+		for _, delimiter := range []string{"```", "`", "*", "_"} {
+			if strings.HasPrefix(rest, delimiter) {
+				open, start = delimiter, position
+				position += len(delimiter)
+				opened = true
+				break
+			}
+		}
+		if !opened {
+			position++
+		}
+	}
+
+	if open != "" {
+		return fmt.Errorf("unclosed %s starting at byte %d", markdownEntities[open], start)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package quizlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckMarkdown(t *testing.T) {
+	tests := []struct {
+		text    string
+		wantErr string // part of the error, empty if the text is valid
+	}{
+		{text: ""},
+		{text: "Plain text without entities."},
+		{text: "*bold*, _italic_, `code` and [a link](https://go.dev)"},
+		{text: "```\nfunc main() {\n\tfmt.Println(\"*\")\n}\n```"},
+		{text: "Entities inside code are literal: `a_b * c`"},
+		{text: "Escaped \\_underscore\\_, \\*star\\* and \\[bracket"},
+		{text: "[link text] without a URL"},
+		{text: "snake_case", wantErr: "unclosed italic text starting at byte 5"},
+		{text: "2 * 3", wantErr: "unclosed bold text"},
+		{text: "*bold _italic*_", wantErr: "unclosed italic text"},
+		{text: "`unclosed code", wantErr: "unclosed code span"},
+		{text: "```\nunclosed block", wantErr: "unclosed pre block"},
+		{text: "[unclosed link", wantErr: "unclosed link text starting at byte 0"},
+	}
+
+	for _, tt := range tests {
+		err := CheckMarkdown(tt.text)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("CheckMarkdown(%q) = %v, want no error", tt.text, err)
+		case tt.wantErr != "" && err == nil:
+			t.Errorf("CheckMarkdown(%q) found no error, want %q", tt.text, tt.wantErr)
+		case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+			t.Errorf("CheckMarkdown(%q) = %v, want %q", tt.text, err, tt.wantErr)
+		}
+	}
+}
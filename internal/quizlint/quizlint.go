@@ -0,0 +1,123 @@
+// Package quizlint finds quiz questions that can't be answered or don't render
+// properly in Telegram, and optionally disables them.
+package quizlint
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+	"github.com/amiosamu/interview-match-bot/internal/service"
+)
+
+// MaxButtonTextLength is the longest option text that still fits on an inline
+// button; longer labels get truncated, especially on mobile clients
+const MaxButtonTextLength = 64
+
+// Severity tells how bad an issue is
+type Severity string
+
+const (
+	// SeverityError marks questions that can't be answered or fail to render
+	SeverityError Severity = "error"
+	// SeverityWarning marks questions that work but are confusing or hard to read
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a question
+type Issue struct {
+	QuestionID int
+	Severity   Severity
+	Message    string
+}
+
+// String formats the issue for logs and command output
+func (i Issue) String() string {
+	return fmt.Sprintf("question %d: %s: %s", i.QuestionID, i.Severity, i.Message)
+}
+
+// Report is the result of linting the question bank
+type Report struct {
+	Checked  int
+	Issues   []Issue
+	Disabled []int // IDs of questions disabled because of errors
+}
+
+// Lint checks a single question
+func Lint(q *models.QuizQuestion) []Issue {
+	var issues []Issue
+	add := func(severity Severity, format string, args ...interface{}) {
+		issues = append(issues, Issue{QuestionID: q.ID, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if err := q.Validate(); err != nil {
+		for _, message := range strings.Split(err.Error(), "\n") {
+			add(SeverityError, "%s", message)
+		}
+	}
+
+	seen := make(map[string]int)
+	for i, option := range q.AnswerOptions {
+		if first, exists := seen[option]; exists {
+			add(SeverityWarning, "options %d and %d are both %q", first+1, i+1, option)
+		}
+		seen[option] = i
+
+		if length := len([]rune(option)); length > MaxButtonTextLength {
+			add(SeverityWarning, "option %d is %d characters long, buttons show at most %d", i+1, length, MaxButtonTextLength)
+		}
+	}
+
+	// These are sent with Markdown parse mode, Telegram rejects the whole message if they don't parse
+	if err := CheckMarkdown(q.QuestionText); err != nil {
+		add(SeverityError, "question text is not valid Markdown: %v", err)
+	}
+	if err := CheckMarkdown(q.Explanation); err != nil {
+		add(SeverityError, "explanation is not valid Markdown: %v", err)
+	}
+	if err := CheckMarkdown(q.Hint); err != nil {
+		add(SeverityError, "hint is not valid Markdown: %v", err)
+	}
+	if q.Type != models.QuestionTypeOutput {
+		if err := CheckMarkdown("*" + q.CorrectAnswerText() + "*"); err != nil {
+			add(SeverityError, "correct answer breaks the Markdown of the feedback message: %v", err)
+		}
+	}
+
+	return issues
+}
+
+// HasErrors reports whether any of the issues is an error
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Run lints all questions, optionally limited to one language.
+// With disable set, active questions with errors are disabled.
+func Run(ctx context.Context, quizService *service.QuizService, language string, disable bool) (*Report, error) {
+	questions, err := quizService.ListQuestions(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Checked: len(questions)}
+	for _, q := range questions {
+		issues := Lint(q)
+		report.Issues = append(report.Issues, issues...)
+
+		if disable && q.Active && HasErrors(issues) {
+			if err := quizService.SetQuestionActive(ctx, q.ID, false); err != nil {
+				return report, err
+			}
+			report.Disabled = append(report.Disabled, q.ID)
+		}
+	}
+
+	return report, nil
+}
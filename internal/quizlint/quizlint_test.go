@@ -0,0 +1,45 @@
+package quizlint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+func TestLintHint(t *testing.T) {
+	tests := []struct {
+		hint      string
+		wantError bool
+	}{
+		{hint: ""},
+		{hint: "Look at the `defer` statement."},
+		{hint: "Think of *slices* and their backing arrays."},
+		{hint: "What does sync\\_once do?"},
+		{hint: "Remember sync_once.", wantError: true},
+		{hint: "The answer is *not* a *pointer", wantError: true},
+	}
+
+	for _, tt := range tests {
+		question := &models.QuizQuestion{
+			ID:                 1,
+			Language:           "go",
+			Type:               models.QuestionTypeSingle,
+			QuestionText:       "Which one?",
+			AnswerOptions:      []string{"This", "That"},
+			CorrectAnswer:      "This",
+			CorrectOptionIndex: 0,
+			Hint:               tt.hint,
+		}
+
+		hintError := false
+		for _, issue := range Lint(question) {
+			if strings.HasPrefix(issue.Message, "hint") && issue.Severity == SeverityError {
+				hintError = true
+			}
+		}
+		if hintError != tt.wantError {
+			t.Errorf("Lint of hint %q reported a hint error: %v, want %v", tt.hint, hintError, tt.wantError)
+		}
+	}
+}
@@ -0,0 +1,458 @@
+// Package sender delivers the bot's outgoing messages within Telegram's rate limits.
+//
+// Telegram accepts about 30 messages per second from a bot, about one per second in a
+// private chat and 20 per minute in a group; going faster gets requests rejected with
+// "429 Too Many Requests". The sender keeps a token bucket for the bot and one per chat.
+// Sends are queued per chat and made by goroutines of the sender once both buckets have
+// a token for them, in the order they were posted, so the callers never wait for their
+// turn. A send that would have to wait too long is dropped instead. Requests rejected
+// with 429 anyway are retried after the time Telegram asks for.
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// ErrDropped is returned for a send that would have had to wait longer than Limits.MaxWait
+var ErrDropped = errors.New("message dropped: rate limit queue is full")
+
+// ErrClosed is returned for a send posted after the sender was shut down
+var ErrClosed = errors.New("message dropped: the sender is shut down")
+
+// API is the part of the Telegram Bot API the sender wraps. *tgbotapi.BotAPI implements it.
+type API interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error)
+	GetChatMembersCount(config tgbotapi.ChatMemberCountConfig) (int, error)
+}
+
+// Limits are the rates the sender keeps to. Rates are in sends per second.
+type Limits struct {
+	GlobalRate  float64
+	GlobalBurst int
+	ChatRate    float64 // private chats
+	ChatBurst   int
+	GroupRate   float64 // group chats
+	GroupBurst  int
+	MaxWait     time.Duration // longest a send waits for its turn or a retry before it is dropped
+	MaxRetries  int           // retries of a send rejected with 429
+}
+
+// DefaultLimits stay a little below the limits Telegram documents
+var DefaultLimits = Limits{
+	GlobalRate:  25,
+	GlobalBurst: 25,
+	ChatRate:    1,
+	ChatBurst:   3,
+	GroupRate:   20.0 / 60,
+	GroupBurst:  5,
+	MaxWait:     30 * time.Second,
+	MaxRetries:  3,
+}
+
+// idleBucketAge is how long a chat's bucket is kept after its last send
+const idleBucketAge = 10 * time.Minute
+
+// Stats counts what happened to the sends made through a Sender
+type Stats struct {
+	Sent    int64 // sends Telegram accepted
+	Retried int64 // retries after a 429
+	Dropped int64 // sends dropped because of the rate limits
+	Failed  int64 // sends Telegram rejected for another reason
+	Blocked int64 // sends to chats that blocked the bot
+}
+
+// Sender rate limits the messages sent through an API.
+// It implements the same methods as the API, so it can be used in its place.
+type Sender struct {
+	api    API
+	limits Limits
+
+	mutex     sync.Mutex
+	global    *bucket
+	chats     map[int64]*bucket
+	queues    map[int64][]*delivery // sends by chat; a chat is present while a goroutine makes its sends
+	pending   int                   // sends queued or being made
+	closed    bool
+	done      sync.WaitGroup
+	lastSweep time.Time
+	onBlocked func(chatID int64)
+
+	sent, retried, dropped, failed, blocked atomic.Int64
+}
+
+// delivery is a send waiting for its turn
+type delivery struct {
+	c       tgbotapi.Chattable
+	chatID  int64
+	limited bool      // the send counts against the rate limits of its chat
+	at      time.Time // when the rate limits let it go out
+	request func() error
+	done    func(error)
+}
+
+// New creates a Sender that sends through api
+func New(api API, limits Limits) *Sender {
+	return &Sender{
+		api:       api,
+		limits:    limits,
+		global:    newBucket(limits.GlobalRate, limits.GlobalBurst, time.Now()),
+		chats:     make(map[int64]*bucket),
+		queues:    make(map[int64][]*delivery),
+		lastSweep: time.Now(),
+	}
+}
+
+// OnBlocked sets a function called with the chat ID when a send fails because the user
+// blocked the bot or deleted their account, so the bot can stop messaging them
+func (s *Sender) OnBlocked(handler func(chatID int64)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.onBlocked = handler
+}
+
+// Post queues a message, edit or poll behind the earlier sends to its chat and returns at once.
+// The send is made once both the bot and the chat have a token left, and then done is called
+// with the result, unless it is nil. The send is dropped with ErrDropped if it would have to
+// wait longer than Limits.MaxWait.
+func (s *Sender) Post(c tgbotapi.Chattable, done func(tgbotapi.Message, error)) {
+	var message tgbotapi.Message
+	s.enqueue(c, func() (err error) {
+		message, err = s.api.Send(c)
+		return err
+	}, func(err error) {
+		if done != nil {
+			done(message, err)
+		}
+	})
+}
+
+// PostRequest queues a request the same way as Post. Requests that don't send anything to a chat,
+// such as callback answers and webhook settings, aren't rate limited but are retried on 429.
+func (s *Sender) PostRequest(c tgbotapi.Chattable, done func(*tgbotapi.APIResponse, error)) {
+	var response *tgbotapi.APIResponse
+	s.enqueue(c, func() (err error) {
+		response, err = s.api.Request(c)
+		return err
+	}, func(err error) {
+		if done != nil {
+			done(response, err)
+		}
+	})
+}
+
+// Send posts a message, edit or poll and waits until it was sent.
+// It returns ErrDropped without sending if that takes longer than Limits.MaxWait.
+func (s *Sender) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	type result struct {
+		message tgbotapi.Message
+		err     error
+	}
+	results := make(chan result, 1)
+	s.Post(c, func(message tgbotapi.Message, err error) {
+		results <- result{message, err}
+	})
+	r := <-results
+	return r.message, r.err
+}
+
+// Request posts a request and waits until it was made
+func (s *Sender) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	type result struct {
+		response *tgbotapi.APIResponse
+		err      error
+	}
+	results := make(chan result, 1)
+	s.PostRequest(c, func(response *tgbotapi.APIResponse, err error) {
+		results <- result{response, err}
+	})
+	r := <-results
+	return r.response, r.err
+}
+
+// GetChatMember looks up a chat member without rate limiting
+func (s *Sender) GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error) {
+	return s.api.GetChatMember(config)
+}
+
+// GetChatMembersCount counts the members of a chat without rate limiting
+func (s *Sender) GetChatMembersCount(config tgbotapi.ChatMemberCountConfig) (int, error) {
+	return s.api.GetChatMembersCount(config)
+}
+
+// Stats returns the counts of the sends made so far
+func (s *Sender) Stats() Stats {
+	return Stats{
+		Sent:    s.sent.Load(),
+		Retried: s.retried.Load(),
+		Dropped: s.dropped.Load(),
+		Failed:  s.failed.Load(),
+		Blocked: s.blocked.Load(),
+	}
+}
+
+// Pending returns the number of sends queued or being made
+func (s *Sender) Pending() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.pending
+}
+
+// Shutdown stops accepting sends and waits until the ones already posted were made.
+// If ctx is done first, it returns the context's error without waiting for them.
+func (s *Sender) Shutdown(ctx context.Context) error {
+	s.mutex.Lock()
+	s.closed = true
+	s.mutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.done.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// enqueue reserves the turn of a request and queues it behind the other requests of its chat.
+// Requests that aren't rate limited are made right away. done is called with the result.
+func (s *Sender) enqueue(c tgbotapi.Chattable, request func() error, done func(error)) {
+	chatID, limited := ChatOf(c)
+	d := &delivery{c: c, chatID: chatID, limited: limited, request: request, done: done}
+
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		s.dropped.Add(1)
+		slog.Warn("Dropped a request: the sender is shut down", "request", fmt.Sprintf("%T", c), "chat_id", chatID)
+		done(ErrClosed)
+		return
+	}
+
+	if !limited {
+		s.pending++
+		s.done.Add(1)
+		s.mutex.Unlock()
+		go func() {
+			defer s.done.Done()
+			s.deliver(d)
+		}()
+		return
+	}
+
+	at, ok := s.reserve(chatID, time.Now())
+	if !ok {
+		s.mutex.Unlock()
+		s.dropped.Add(1)
+		slog.Warn("Dropped a request: it would wait too long", "request", fmt.Sprintf("%T", c), "chat_id", chatID, "max_wait", s.limits.MaxWait)
+		done(ErrDropped)
+		return
+	}
+	d.at = at
+
+	s.pending++
+	queue, active := s.queues[chatID]
+	s.queues[chatID] = append(queue, d)
+	if !active {
+		s.done.Add(1)
+		go s.drain(chatID)
+	}
+	s.mutex.Unlock()
+}
+
+// drain makes the queued requests of a chat one after another, each at its turn, until none are left
+func (s *Sender) drain(chatID int64) {
+	defer s.done.Done()
+
+	for {
+		s.mutex.Lock()
+		queue := s.queues[chatID]
+		if len(queue) == 0 {
+			delete(s.queues, chatID)
+			s.mutex.Unlock()
+			return
+		}
+		d := queue[0]
+		s.queues[chatID] = queue[1:]
+		s.mutex.Unlock()
+
+		time.Sleep(time.Until(d.at))
+		s.deliver(d)
+	}
+}
+
+// deliver makes a request whose turn came and reports the result
+func (s *Sender) deliver(d *delivery) {
+	err := s.do(d.c, d.chatID, d.limited, d.request)
+	d.done(err)
+
+	s.mutex.Lock()
+	s.pending--
+	s.mutex.Unlock()
+}
+
+// do makes a request and retries it while Telegram asks to slow down
+func (s *Sender) do(c tgbotapi.Chattable, chatID int64, limited bool, request func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := request()
+		if err == nil {
+			s.sent.Add(1)
+			return nil
+		}
+
+		var apiErr *tgbotapi.Error
+		if !errors.As(err, &apiErr) {
+			s.failed.Add(1)
+			return err
+		}
+
+		switch {
+		case apiErr.Code == 429 && attempt < s.limits.MaxRetries:
+			retryAfter := time.Duration(apiErr.RetryAfter) * time.Second
+			if retryAfter > s.limits.MaxWait {
+				s.dropped.Add(1)
+				slog.Warn("Dropped a request: Telegram asked to wait too long", "request", fmt.Sprintf("%T", c), "chat_id", chatID, "retry_after", retryAfter)
+				return err
+			}
+			s.retried.Add(1)
+			time.Sleep(retryAfter)
+
+		case limited && isBlocked(apiErr):
+			s.blocked.Add(1)
+			s.mutex.Lock()
+			onBlocked := s.onBlocked
+			s.mutex.Unlock()
+			if onBlocked != nil {
+				onBlocked(chatID)
+			}
+			return err
+
+		default:
+			s.failed.Add(1)
+			return err
+		}
+	}
+}
+
+// reserve takes a token from the bot's bucket and the chat's bucket and returns when both are
+// available. It returns false, taking nothing, if that is later than MaxWait from now.
+// The caller must hold the mutex.
+func (s *Sender) reserve(chatID int64, now time.Time) (time.Time, bool) {
+	s.sweep(now)
+
+	chat, exists := s.chats[chatID]
+	if !exists {
+		if chatID < 0 {
+			chat = newBucket(s.limits.GroupRate, s.limits.GroupBurst, now)
+		} else {
+			chat = newBucket(s.limits.ChatRate, s.limits.ChatBurst, now)
+		}
+		s.chats[chatID] = chat
+	}
+
+	// Both tokens are taken now, so a chat with a backlog holds up the others a little,
+	// but the bot never goes faster than its rate
+	at := chat.take(now)
+	if globalAt := s.global.take(now); globalAt.After(at) {
+		at = globalAt
+	}
+
+	if at.Sub(now) > s.limits.MaxWait {
+		chat.give()
+		s.global.give()
+		return time.Time{}, false
+	}
+
+	return at, true
+}
+
+// sweep removes the buckets of chats without recent sends. The caller must hold the mutex.
+func (s *Sender) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < idleBucketAge {
+		return
+	}
+	s.lastSweep = now
+
+	for chatID, chat := range s.chats {
+		if now.Sub(chat.last) > idleBucketAge {
+			delete(s.chats, chatID)
+		}
+	}
+}
+
+// ChatOf returns the chat a request sends something to.
+// Requests without one, such as callback answers and inline message edits, aren't rate limited.
+func ChatOf(c tgbotapi.Chattable) (int64, bool) {
+	switch config := c.(type) {
+	case tgbotapi.MessageConfig:
+		return config.ChatID, true
+	case tgbotapi.SendPollConfig:
+		return config.ChatID, true
+	case tgbotapi.EditMessageTextConfig:
+		return config.ChatID, config.InlineMessageID == ""
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return config.ChatID, config.InlineMessageID == ""
+	case tgbotapi.StopPollConfig:
+		return config.ChatID, config.InlineMessageID == ""
+	}
+	return 0, false
+}
+
+// isBlocked reports whether an error means the bot can't message the chat anymore
+func isBlocked(err *tgbotapi.Error) bool {
+	if err.Code != 403 {
+		return false
+	}
+	return strings.Contains(err.Message, "bot was blocked by the user") ||
+		strings.Contains(err.Message, "user is deactivated") ||
+		strings.Contains(err.Message, "bot can't initiate conversation") ||
+		strings.Contains(err.Message, "bot was kicked")
+}
+
+// bucket is a token bucket. Tokens may be taken ahead of time, the balance then goes negative.
+type bucket struct {
+	rate   float64 // tokens added per second
+	burst  float64 // most tokens the bucket holds
+	tokens float64
+	last   time.Time // time the balance was last brought up to date
+}
+
+func newBucket(rate float64, burst int, now time.Time) *bucket {
+	return &bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// take takes a token and returns when it is available, now or later
+func (b *bucket) take(now time.Time) time.Time {
+	if now.After(b.last) {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return now
+	}
+	return b.last.Add(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+}
+
+// give returns a token taken for a send that was dropped
+func (b *bucket) give() {
+	b.tokens++
+}
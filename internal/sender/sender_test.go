@@ -0,0 +1,208 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeAPI records when messages were sent and fails the first sends with the queued errors
+type fakeAPI struct {
+	mutex  sync.Mutex
+	sent   []time.Time
+	errors []error
+}
+
+func (f *fakeAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	_, err := f.Request(c)
+	return tgbotapi.Message{}, err
+}
+
+func (f *fakeAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.errors) > 0 {
+		err := f.errors[0]
+		f.errors = f.errors[1:]
+		return nil, err
+	}
+
+	f.sent = append(f.sent, time.Now())
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (f *fakeAPI) GetChatMember(config tgbotapi.GetChatMemberConfig) (tgbotapi.ChatMember, error) {
+	return tgbotapi.ChatMember{}, nil
+}
+
+func (f *fakeAPI) GetChatMembersCount(config tgbotapi.ChatMemberCountConfig) (int, error) {
+	return 0, nil
+}
+
+// testLimits allow two sends per chat and ten overall right away, then one every 100ms
+var testLimits = Limits{
+	GlobalRate:  10,
+	GlobalBurst: 10,
+	ChatRate:    10,
+	ChatBurst:   2,
+	GroupRate:   10,
+	GroupBurst:  2,
+	MaxWait:     time.Second,
+	MaxRetries:  2,
+}
+
+func TestChatRateLimit(t *testing.T) {
+	api := &fakeAPI{}
+	s := New(api, testLimits)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := s.Send(tgbotapi.NewMessage(1, "hello")); err != nil {
+			t.Fatalf("sending: %v", err)
+		}
+	}
+
+	// The burst goes out right away, the rest one per 100ms
+	if elapsed := api.sent[1].Sub(start); elapsed > 50*time.Millisecond {
+		t.Errorf("second message waited %s, want no wait", elapsed)
+	}
+	if elapsed := api.sent[3].Sub(start); elapsed < 150*time.Millisecond {
+		t.Errorf("fourth message went out after %s, want at least 200ms", elapsed)
+	}
+
+	// Other chats have buckets of their own
+	other := time.Now()
+	if _, err := s.Send(tgbotapi.NewMessage(2, "hello")); err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+	if elapsed := time.Since(other); elapsed > 50*time.Millisecond {
+		t.Errorf("message to another chat waited %s, want no wait", elapsed)
+	}
+
+	if stats := s.Stats(); stats.Sent != 5 {
+		t.Errorf("got %d sent, want 5", stats.Sent)
+	}
+}
+
+func TestDropWhenWaitTooLong(t *testing.T) {
+	api := &fakeAPI{}
+	limits := testLimits
+	limits.MaxWait = 150 * time.Millisecond
+	s := New(api, limits)
+
+	// Sends made at once queue up: the burst goes out, the next one waits 100ms,
+	// the others would wait too long
+	results := make(chan error)
+	for i := 0; i < 6; i++ {
+		go func() {
+			_, err := s.Send(tgbotapi.NewMessage(1, "hello"))
+			results <- err
+		}()
+	}
+
+	var dropped int
+	for i := 0; i < 6; i++ {
+		if err := <-results; errors.Is(err, ErrDropped) {
+			dropped++
+		} else if err != nil {
+			t.Fatalf("sending: %v", err)
+		}
+	}
+
+	if dropped != 3 {
+		t.Errorf("%d messages were dropped, want 3", dropped)
+	}
+	if stats := s.Stats(); stats.Dropped != int64(dropped) || stats.Sent != int64(6-dropped) {
+		t.Errorf("got stats %+v, want %d dropped", stats, dropped)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	api := &fakeAPI{errors: []error{
+		&tgbotapi.Error{Code: 429, Message: "Too Many Requests: retry after 0"},
+	}}
+	s := New(api, testLimits)
+
+	if _, err := s.Send(tgbotapi.NewMessage(1, "hello")); err != nil {
+		t.Fatalf("sending: %v", err)
+	}
+
+	if stats := s.Stats(); stats.Sent != 1 || stats.Retried != 1 {
+		t.Errorf("got stats %+v, want 1 sent after 1 retry", stats)
+	}
+}
+
+func TestBlockedChat(t *testing.T) {
+	blocked := &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}
+	api := &fakeAPI{errors: []error{blocked}}
+	s := New(api, testLimits)
+
+	var blockedChats []int64
+	s.OnBlocked(func(chatID int64) {
+		blockedChats = append(blockedChats, chatID)
+	})
+
+	if _, err := s.Send(tgbotapi.NewMessage(42, "hello")); err == nil {
+		t.Fatal("sending to a chat that blocked the bot succeeded")
+	}
+
+	if len(blockedChats) != 1 || blockedChats[0] != 42 {
+		t.Errorf("got blocked chats %v, want [42]", blockedChats)
+	}
+	if stats := s.Stats(); stats.Blocked != 1 || stats.Sent != 0 {
+		t.Errorf("got stats %+v, want 1 blocked", stats)
+	}
+}
+
+func TestPostDoesNotWait(t *testing.T) {
+	api := &fakeAPI{}
+	s := New(api, testLimits)
+
+	// The sends beyond the burst are queued instead of holding up the caller
+	start := time.Now()
+	var mutex sync.Mutex
+	var order []int
+	for i := 0; i < 4; i++ {
+		i := i
+		s.Post(tgbotapi.NewMessage(1, "hello"), func(message tgbotapi.Message, err error) {
+			if err != nil {
+				t.Errorf("sending: %v", err)
+			}
+			mutex.Lock()
+			order = append(order, i)
+			mutex.Unlock()
+		})
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("posting took %s, want no wait", elapsed)
+	}
+	if pending := s.Pending(); pending == 0 {
+		t.Error("got no pending sends right after posting")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Shutdown(ctx); err != nil {
+		t.Fatalf("shutting down: %v", err)
+	}
+
+	// Shutdown waited for the queued sends, which went out in the order they were posted
+	if len(order) != 4 || order[0] != 0 || order[1] != 1 || order[2] != 2 || order[3] != 3 {
+		t.Errorf("sends were made in the order %v, want [0 1 2 3]", order)
+	}
+	if elapsed := api.sent[3].Sub(start); elapsed < 150*time.Millisecond {
+		t.Errorf("fourth message went out after %s, want at least 200ms", elapsed)
+	}
+	if pending := s.Pending(); pending != 0 {
+		t.Errorf("got %d pending sends after shutdown, want 0", pending)
+	}
+
+	if _, err := s.Send(tgbotapi.NewMessage(1, "hello")); !errors.Is(err, ErrClosed) {
+		t.Errorf("sending after shutdown = %v, want ErrClosed", err)
+	}
+}
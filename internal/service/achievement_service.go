@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// Achievement thresholds
+const (
+	centuryAnswers   = 100
+	weekStreakLength = 7
+)
+
+// achievementRule decides whether a user has earned an achievement.
+// The rule is only checked on the events that can change its outcome.
+type achievementRule struct {
+	achievementID string
+	events        []string
+	earned        func(s *AchievementService, ctx context.Context, userID int64, now time.Time) (bool, error)
+}
+
+// achievementRules are checked in the order of models.Achievements
+var achievementRules = []achievementRule{
+	{
+		achievementID: models.AchievementPerfectQuiz,
+		events:        []string{models.EventQuizCompleted},
+		earned:        (*AchievementService).hasPerfectQuiz,
+	},
+	{
+		achievementID: models.AchievementCentury,
+		events:        []string{models.EventQuizCompleted},
+		earned:        (*AchievementService).hasCenturyAnswers,
+	},
+	{
+		achievementID: models.AchievementWeekStreak,
+		events:        []string{models.EventDailyAnswered},
+		earned:        (*AchievementService).hasWeekStreak,
+	},
+	{
+		achievementID: models.AchievementAllCategories,
+		events:        []string{models.EventQuizCompleted},
+		earned:        (*AchievementService).hasAllCategories,
+	},
+	{
+		achievementID: models.AchievementFirstPartner,
+		events:        []string{models.EventInterviewCompleted},
+		earned: func(*AchievementService, context.Context, int64, time.Time) (bool, error) {
+			return true, nil // the completed interview itself is the milestone
+		},
+	},
+}
+
+// AchievementService evaluates and stores user achievements
+type AchievementService struct {
+	db *sql.DB
+}
+
+// NewAchievementService creates a new AchievementService
+func NewAchievementService(db *sql.DB) *AchievementService {
+	return &AchievementService{db: db}
+}
+
+// Evaluate checks the achievements an event can unlock and stores the ones the user earned.
+// It returns the achievements unlocked by this call.
+func (s *AchievementService) Evaluate(ctx context.Context, userID int64, event string, now time.Time) ([]models.Achievement, error) {
+	unlocked, err := s.GetUnlocked(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	has := make(map[string]bool)
+	for _, achievement := range unlocked {
+		has[achievement.ID] = true
+	}
+
+	var newlyUnlocked []models.Achievement
+	for _, rule := range rulesFor(event, has) {
+		earned, err := rule.earned(s, ctx, userID, now)
+		if err != nil {
+			return newlyUnlocked, fmt.Errorf("error checking achievement %s: %w", rule.achievementID, err)
+		}
+		if !earned {
+			continue
+		}
+
+		inserted, err := s.unlock(ctx, userID, rule.achievementID)
+		if err != nil {
+			return newlyUnlocked, err
+		}
+
+		// A concurrent evaluation may have unlocked it first
+		if inserted {
+			achievement, _ := models.FindAchievement(rule.achievementID)
+			newlyUnlocked = append(newlyUnlocked, achievement)
+		}
+	}
+
+	return newlyUnlocked, nil
+}
+
+// rulesFor returns the rules an event can change the outcome of, leaving out achievements already unlocked
+func rulesFor(event string, unlocked map[string]bool) []achievementRule {
+	var rules []achievementRule
+	for _, rule := range achievementRules {
+		if !unlocked[rule.achievementID] && contains(rule.events, event) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// GetUnlocked returns the achievements a user has unlocked, oldest first
+func (s *AchievementService) GetUnlocked(ctx context.Context, userID int64) ([]models.UnlockedAchievement, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT achievement_id, unlocked_at
+		FROM user_achievements
+		WHERE user_id = $1
+		ORDER BY unlocked_at
+	`, userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying achievements: %w", err)
+	}
+	defer rows.Close()
+
+	var unlocked []models.UnlockedAchievement
+	for rows.Next() {
+		var id string
+		var unlockedAt time.Time
+		if err := rows.Scan(&id, &unlockedAt); err != nil {
+			return nil, fmt.Errorf("error scanning achievement: %w", err)
+		}
+
+		// Achievements that were retired are skipped
+		achievement, ok := models.FindAchievement(id)
+		if !ok {
+			continue
+		}
+		unlocked = append(unlocked, models.UnlockedAchievement{Achievement: achievement, UnlockedAt: unlockedAt})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating achievements: %w", err)
+	}
+
+	return unlocked, nil
+}
+
+// unlock stores an achievement for a user, reporting whether it was new
+func (s *AchievementService) unlock(ctx context.Context, userID int64, achievementID string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_achievements (user_id, achievement_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, achievement_id) DO NOTHING
+	`, userID, achievementID)
+
+	if err != nil {
+		return false, fmt.Errorf("error unlocking achievement: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// hasPerfectQuiz reports whether the user completed a quiz with every question answered correctly without hints
+func (s *AchievementService) hasPerfectQuiz(ctx context.Context, userID int64, _ time.Time) (bool, error) {
+	var perfect bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM user_quiz_sessions
+			WHERE user_id = $1 AND chat_id IS NULL AND completed_at IS NOT NULL
+				AND current_question_index > 0
+				AND current_question_index = jsonb_array_length(question_ids)
+				AND correct_answers = current_question_index AND hinted_answers = 0
+		)
+	`, userID).Scan(&perfect)
+
+	if err != nil {
+		return false, fmt.Errorf("error querying perfect quizzes: %w", err)
+	}
+
+	return perfect, nil
+}
+
+// hasCenturyAnswers reports whether the user answered enough quiz questions, group quizzes included
+func (s *AchievementService) hasCenturyAnswers(ctx context.Context, userID int64, _ time.Time) (bool, error) {
+	var answers int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM user_quiz_answers
+		WHERE user_id = $1 AND NOT skipped
+	`, userID).Scan(&answers)
+
+	if err != nil {
+		return false, fmt.Errorf("error counting answers: %w", err)
+	}
+
+	return answers >= centuryAnswers, nil
+}
+
+// hasWeekStreak reports whether the user ever kept a long enough daily challenge streak
+func (s *AchievementService) hasWeekStreak(ctx context.Context, userID int64, now time.Time) (bool, error) {
+	streak, err := NewDailyService(s.db).GetDailyStreak(ctx, userID, now)
+	if err != nil {
+		return false, err
+	}
+
+	return streak.Longest >= weekStreakLength, nil
+}
+
+// hasAllCategories reports whether the user answered questions of every category of some language
+func (s *AchievementService) hasAllCategories(ctx context.Context, userID int64, _ time.Time) (bool, error) {
+	var covered bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM quiz_questions q
+			WHERE q.active
+			GROUP BY q.language
+			HAVING COUNT(DISTINCT q.category) = (
+				SELECT COUNT(DISTINCT aq.category)
+				FROM user_quiz_answers a
+				JOIN quiz_questions aq ON aq.id = a.question_id
+				WHERE a.user_id = $1 AND NOT a.skipped AND aq.language = q.language AND aq.active
+			)
+		)
+	`, userID).Scan(&covered)
+
+	if err != nil {
+		return false, fmt.Errorf("error querying answered categories: %w", err)
+	}
+
+	return covered, nil
+}
+
+// contains reports whether a string slice contains a value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+func TestEveryAchievementHasARule(t *testing.T) {
+	rules := make(map[string]int)
+	for _, rule := range achievementRules {
+		if _, ok := models.FindAchievement(rule.achievementID); !ok {
+			t.Errorf("rule for unknown achievement %q", rule.achievementID)
+		}
+		if len(rule.events) == 0 {
+			t.Errorf("rule for %q is never checked", rule.achievementID)
+		}
+		rules[rule.achievementID]++
+	}
+
+	for _, achievement := range models.Achievements {
+		if rules[achievement.ID] != 1 {
+			t.Errorf("achievement %q has %d rules, want 1", achievement.ID, rules[achievement.ID])
+		}
+	}
+}
+
+func TestRulesFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    string
+		unlocked []string
+		want     []string
+	}{
+		{
+			name:  "quiz completed",
+			event: models.EventQuizCompleted,
+			want:  []string{models.AchievementPerfectQuiz, models.AchievementCentury, models.AchievementAllCategories},
+		},
+		{
+			name:     "unlocked achievements are not checked again",
+			event:    models.EventQuizCompleted,
+			unlocked: []string{models.AchievementCentury},
+			want:     []string{models.AchievementPerfectQuiz, models.AchievementAllCategories},
+		},
+		{
+			name:  "daily challenge answered",
+			event: models.EventDailyAnswered,
+			want:  []string{models.AchievementWeekStreak},
+		},
+		{
+			name:  "mock interview completed",
+			event: models.EventInterviewCompleted,
+			want:  []string{models.AchievementFirstPartner},
+		},
+		{
+			name:     "first mock interview only counts once",
+			event:    models.EventInterviewCompleted,
+			unlocked: []string{models.AchievementFirstPartner},
+		},
+		{
+			name:  "finding a match is no milestone",
+			event: "match_found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unlocked := make(map[string]bool)
+			for _, id := range tt.unlocked {
+				unlocked[id] = true
+			}
+
+			var got []string
+			for _, rule := range rulesFor(tt.event, unlocked) {
+				got = append(got, rule.achievementID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rulesFor(%q) checks %v, want %v", tt.event, got, tt.want)
+			}
+		})
+	}
+}
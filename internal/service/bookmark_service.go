@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// BookmarkService handles the questions users saved to revisit
+type BookmarkService struct {
+	db *sql.DB
+}
+
+// NewBookmarkService creates a new BookmarkService
+func NewBookmarkService(db *sql.DB) *BookmarkService {
+	return &BookmarkService{db: db}
+}
+
+// AddBookmark saves a question for a user. It returns false if the question was already bookmarked.
+func (s *BookmarkService) AddBookmark(ctx context.Context, userID int64, questionID int) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO question_bookmarks (user_id, question_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, question_id) DO NOTHING
+	`, userID, questionID)
+
+	if err != nil {
+		return false, fmt.Errorf("error adding bookmark: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// RemoveBookmark removes a question from a user's bookmarks. It returns false if it wasn't bookmarked.
+func (s *BookmarkService) RemoveBookmark(ctx context.Context, userID int64, questionID int) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM question_bookmarks
+		WHERE user_id = $1 AND question_id = $2
+	`, userID, questionID)
+
+	if err != nil {
+		return false, fmt.Errorf("error removing bookmark: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// CountBookmarks returns the number of questions a user bookmarked
+func (s *BookmarkService) CountBookmarks(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM question_bookmarks
+		WHERE user_id = $1
+	`, userID).Scan(&count)
+
+	if err != nil {
+		return 0, fmt.Errorf("error counting bookmarks: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetBookmarks returns a page of a user's bookmarked questions, most recently bookmarked first
+func (s *BookmarkService) GetBookmarks(ctx context.Context, userID int64, limit int, offset int) ([]*models.QuizQuestion, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM quiz_questions
+		JOIN (
+			SELECT question_id, created_at AS bookmarked_at
+			FROM question_bookmarks
+			WHERE user_id = $1
+		) b ON b.question_id = id
+		ORDER BY b.bookmarked_at DESC, id
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []*models.QuizQuestion
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning bookmark row: %w", err)
+		}
+
+		questions = append(questions, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bookmark rows: %w", err)
+	}
+
+	return questions, nil
+}
+
+// GetBookmarkedQuestions retrieves random questions from a user's bookmarks for a quiz.
+// Inactive questions are left out like in any other quiz.
+func (s *BookmarkService) GetBookmarkedQuestions(ctx context.Context, userID int64, limit int) ([]*models.QuizQuestion, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM quiz_questions
+		WHERE id IN (SELECT question_id FROM question_bookmarks WHERE user_id = $1)
+			AND active AND (type <> 'single' OR correct_option_index IS NOT NULL)
+		ORDER BY RANDOM()
+		LIMIT $2
+	`, userID, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying bookmarked questions: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []*models.QuizQuestion
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning bookmarked question row: %w", err)
+		}
+
+		questions = append(questions, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bookmarked question rows: %w", err)
+	}
+
+	return questions, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// DailyService handles the daily challenge question
+type DailyService struct {
+	db *sql.DB
+}
+
+// NewDailyService creates a new DailyService
+func NewDailyService(db *sql.DB) *DailyService {
+	return &DailyService{db: db}
+}
+
+// GetDailyQuestion returns the daily challenge question of a language for a day.
+// Only active single-choice questions are candidates. It returns nil if the language has none.
+func (s *DailyService) GetDailyQuestion(ctx context.Context, language string, day time.Time) (*models.QuizQuestion, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id
+		FROM quiz_questions
+		WHERE language = $1 AND active AND type = 'single' AND correct_option_index IS NOT NULL
+		ORDER BY id
+	`, language)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying daily question candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning daily question candidate: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily question candidates: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	questionID := ids[models.DailyQuestionIndex(day, language, len(ids))]
+	q, err := scanQuestion(s.db.QueryRowContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM quiz_questions
+		WHERE id = $1
+	`, questionID))
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying daily question: %w", err)
+	}
+
+	return q, nil
+}
+
+// RecordDailyAnswer records a user's answer to the daily challenge of a language.
+// Each challenge is answered once; it returns false if the user already answered it.
+func (s *DailyService) RecordDailyAnswer(ctx context.Context, userID int64, day time.Time, language string, questionID int, answerIndex int, isCorrect bool) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO daily_challenge_answers (user_id, challenge_date, language, question_id, answer_index, is_correct)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, challenge_date, language) DO NOTHING
+	`, userID, models.ChallengeDay(day), language, questionID, answerIndex, isCorrect)
+
+	if err != nil {
+		return false, fmt.Errorf("error recording daily answer: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// GetDailyStreak computes a user's daily challenge streaks
+func (s *DailyService) GetDailyStreak(ctx context.Context, userID int64, today time.Time) (models.DailyStreak, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT challenge_date
+		FROM daily_challenge_answers
+		WHERE user_id = $1
+		ORDER BY challenge_date DESC
+	`, userID)
+
+	if err != nil {
+		return models.DailyStreak{}, fmt.Errorf("error querying daily answers: %w", err)
+	}
+	defer rows.Close()
+
+	var days []time.Time
+	for rows.Next() {
+		var day time.Time
+		if err := rows.Scan(&day); err != nil {
+			return models.DailyStreak{}, fmt.Errorf("error scanning daily answer date: %w", err)
+		}
+		days = append(days, day)
+	}
+
+	if err = rows.Err(); err != nil {
+		return models.DailyStreak{}, fmt.Errorf("error iterating daily answer dates: %w", err)
+	}
+
+	return models.CalculateDailyStreak(days, today), nil
+}
+
+// GetPendingDailySubscribers returns the subscribers who haven't received the daily challenge of a day yet.
+// Subscribers who blocked the bot are left out.
+func (s *DailyService) GetPendingDailySubscribers(ctx context.Context, day time.Time) ([]models.DailySubscriber, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT user_id, daily_language
+		FROM user_profiles
+		WHERE daily_language IS NOT NULL AND (daily_sent_on IS NULL OR daily_sent_on < $1) AND blocked_at IS NULL
+		ORDER BY user_id
+	`, models.ChallengeDay(day))
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying daily subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	var subscribers []models.DailySubscriber
+	for rows.Next() {
+		var subscriber models.DailySubscriber
+		if err := rows.Scan(&subscriber.UserID, &subscriber.Language); err != nil {
+			return nil, fmt.Errorf("error scanning daily subscriber: %w", err)
+		}
+		subscribers = append(subscribers, subscriber)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily subscribers: %w", err)
+	}
+
+	return subscribers, nil
+}
+
+// MarkDailySent records that a subscriber received the daily challenge of a day,
+// so a restart on the same day doesn't send it again
+func (s *DailyService) MarkDailySent(ctx context.Context, userID int64, day time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE user_profiles
+		SET daily_sent_on = $2
+		WHERE user_id = $1
+	`, userID, models.ChallengeDay(day))
+
+	if err != nil {
+		return fmt.Errorf("error marking daily challenge as sent: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// DuelService handles quiz duels between two users
+type DuelService struct {
+	db *sql.DB
+}
+
+// NewDuelService creates a new DuelService
+func NewDuelService(db *sql.DB) *DuelService {
+	return &DuelService{db: db}
+}
+
+// CreateDuel creates a pending duel over the given questions.
+// opponentID may be nil when the challenge is shared as an invite link.
+func (s *DuelService) CreateDuel(ctx context.Context, challengerID int64, opponentID *int64, language string, questionIDs []int) (*models.QuizDuel, error) {
+	questionIDsJSON, err := json.Marshal(questionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling question IDs: %w", err)
+	}
+
+	duel := &models.QuizDuel{
+		ChallengerID: challengerID,
+		OpponentID:   opponentID,
+		Language:     language,
+		QuestionIDs:  questionIDs,
+		Status:       models.DuelStatusPending,
+	}
+
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO quiz_duels (challenger_id, opponent_id, language, question_ids)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, challengerID, opponentID, language, questionIDsJSON).Scan(&duel.ID, &duel.CreatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error creating duel: %w", err)
+	}
+
+	return duel, nil
+}
+
+// GetDuel retrieves a duel by ID. It returns nil if the duel doesn't exist.
+func (s *DuelService) GetDuel(ctx context.Context, duelID int) (*models.QuizDuel, error) {
+	var duel models.QuizDuel
+	var opponentID sql.NullInt64
+	var questionIDsJSON string
+	var startedAt, finishedAt sql.NullTime
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, challenger_id, opponent_id, language, question_ids, status, created_at, started_at, finished_at
+		FROM quiz_duels
+		WHERE id = $1
+	`, duelID).Scan(
+		&duel.ID,
+		&duel.ChallengerID,
+		&opponentID,
+		&duel.Language,
+		&questionIDsJSON,
+		&duel.Status,
+		&duel.CreatedAt,
+		&startedAt,
+		&finishedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error querying duel: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(questionIDsJSON), &duel.QuestionIDs); err != nil {
+		return nil, fmt.Errorf("error unmarshaling question IDs: %w", err)
+	}
+
+	if opponentID.Valid {
+		duel.OpponentID = &opponentID.Int64
+	}
+	if startedAt.Valid {
+		duel.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		duel.FinishedAt = &finishedAt.Time
+	}
+
+	return &duel, nil
+}
+
+// AcceptDuel starts a pending duel with the given opponent.
+// It returns false if the duel is no longer pending or was sent to someone else.
+func (s *DuelService) AcceptDuel(ctx context.Context, duelID int, opponentID int64) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE quiz_duels
+		SET opponent_id = $2, status = $3, started_at = NOW()
+		WHERE id = $1 AND status = $4 AND challenger_id <> $2
+			AND (opponent_id IS NULL OR opponent_id = $2)
+	`, duelID, opponentID, models.DuelStatusActive, models.DuelStatusPending)
+
+	if err != nil {
+		return false, fmt.Errorf("error accepting duel: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// DeclineDuel declines a pending duel. It returns false if the duel is no longer pending.
+func (s *DuelService) DeclineDuel(ctx context.Context, duelID int) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE quiz_duels
+		SET status = $2, finished_at = NOW()
+		WHERE id = $1 AND status = $3
+	`, duelID, models.DuelStatusDeclined, models.DuelStatusPending)
+
+	if err != nil {
+		return false, fmt.Errorf("error declining duel: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// GetDuelAnswers returns the participants' answers to one of the duel questions
+func (s *DuelService) GetDuelAnswers(ctx context.Context, duelID int, questionID int) ([]models.DuelAnswer, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.user_id, a.is_correct, a.time_to_answer_ms
+		FROM user_quiz_answers a
+		JOIN user_quiz_sessions s ON s.id = a.session_id
+		WHERE s.duel_id = $1 AND a.question_id = $2
+	`, duelID, questionID)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying duel answers: %w", err)
+	}
+	defer rows.Close()
+
+	var answers []models.DuelAnswer
+	for rows.Next() {
+		var answer models.DuelAnswer
+		var timeToAnswerMs sql.NullInt64
+		if err := rows.Scan(&answer.UserID, &answer.IsCorrect, &timeToAnswerMs); err != nil {
+			return nil, fmt.Errorf("error scanning duel answer: %w", err)
+		}
+
+		if timeToAnswerMs.Valid {
+			ms := int(timeToAnswerMs.Int64)
+			answer.TimeToAnswerMs = &ms
+		}
+
+		answers = append(answers, answer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating duel answers: %w", err)
+	}
+
+	return answers, nil
+}
+
+// ClaimQuestionAnnouncement reserves the announcement of the results of a duel question.
+// It returns true exactly once per question, even if both participants answer at the same time.
+func (s *DuelService) ClaimQuestionAnnouncement(ctx context.Context, duelID int, questionNumber int) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE quiz_duels
+		SET announced_questions = $2
+		WHERE id = $1 AND announced_questions < $2
+	`, duelID, questionNumber)
+
+	if err != nil {
+		return false, fmt.Errorf("error claiming duel announcement: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// FinishDuel marks an active duel as finished once both sessions are complete.
+// It returns true exactly once, for the call that finished the duel.
+func (s *DuelService) FinishDuel(ctx context.Context, duelID int) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE quiz_duels
+		SET status = $2, finished_at = NOW()
+		WHERE id = $1 AND status = $3
+			AND (SELECT COUNT(*) FROM user_quiz_sessions WHERE duel_id = $1 AND completed_at IS NOT NULL) = 2
+	`, duelID, models.DuelStatusFinished, models.DuelStatusActive)
+
+	if err != nil {
+		return false, fmt.Errorf("error finishing duel: %w", err)
+	}
+
+	return rowsAffected(result)
+}
+
+// GetDuelResults returns the overall result of every participant
+func (s *DuelService) GetDuelResults(ctx context.Context, duelID int) ([]models.DuelResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT s.user_id, s.correct_answers, COUNT(a.id), COALESCE(SUM(a.time_to_answer_ms), 0)
+		FROM user_quiz_sessions s
+		LEFT JOIN user_quiz_answers a ON a.session_id = s.id
+		WHERE s.duel_id = $1
+		GROUP BY s.id, s.user_id, s.correct_answers
+	`, duelID)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying duel results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.DuelResult
+	for rows.Next() {
+		var result models.DuelResult
+		if err := rows.Scan(&result.UserID, &result.Correct, &result.Answered, &result.TotalTimeMs); err != nil {
+			return nil, fmt.Errorf("error scanning duel result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating duel results: %w", err)
+	}
+
+	return results, nil
+}
+
+// rowsAffected reports whether a statement changed at least one row
+func rowsAffected(result sql.Result) (bool, error) {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error reading affected rows: %w", err)
+	}
+	return affected > 0, nil
+}
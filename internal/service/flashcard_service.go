@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// FlashcardService handles ungraded flashcard reviews and the familiarity they build up
+type FlashcardService struct {
+	db *sql.DB
+}
+
+// NewFlashcardService creates a new FlashcardService
+func NewFlashcardService(db *sql.DB) *FlashcardService {
+	return &FlashcardService{db: db}
+}
+
+// GetNextFlashcard picks the question a user should review next in a language,
+// or among their bookmarks for models.BookmarksLanguage. The least familiar questions come first,
+// then the ones reviewed longest ago. It returns nil if there are no questions.
+func (s *FlashcardService) GetNextFlashcard(ctx context.Context, userID int64, language string) (*models.QuizQuestion, error) {
+	q, err := scanQuestion(s.db.QueryRowContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM quiz_questions
+		LEFT JOIN (
+			SELECT question_id, familiarity, reviewed_at
+			FROM question_familiarity
+			WHERE user_id = $1
+		) f ON f.question_id = id
+		WHERE active AND (type <> 'single' OR correct_option_index IS NOT NULL)
+			AND (language = $2
+				OR ($2 = $3 AND id IN (SELECT question_id FROM question_bookmarks WHERE user_id = $1)))
+		ORDER BY COALESCE(f.familiarity, 0), f.reviewed_at NULLS FIRST, RANDOM()
+		LIMIT 1
+	`, userID, language, models.BookmarksLanguage))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying next flashcard: %w", err)
+	}
+
+	return q, nil
+}
+
+// RateFlashcard records whether a user knew the answer to a question and returns its new familiarity
+func (s *FlashcardService) RateFlashcard(ctx context.Context, userID int64, questionID int, knew bool) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current int
+	err = tx.QueryRowContext(ctx, `
+		SELECT familiarity
+		FROM question_familiarity
+		WHERE user_id = $1 AND question_id = $2
+		FOR UPDATE
+	`, userID, questionID).Scan(&current)
+
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("error retrieving familiarity: %w", err)
+	}
+
+	familiarity := models.NextFamiliarity(current, knew)
+	knewCount, missedCount := 0, 1
+	if knew {
+		knewCount, missedCount = 1, 0
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO question_familiarity (user_id, question_id, familiarity, knew_count, missed_count)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, question_id) DO UPDATE
+		SET familiarity = EXCLUDED.familiarity,
+			knew_count = question_familiarity.knew_count + EXCLUDED.knew_count,
+			missed_count = question_familiarity.missed_count + EXCLUDED.missed_count,
+			reviewed_at = NOW()
+	`, userID, questionID, familiarity, knewCount, missedCount)
+
+	if err != nil {
+		return 0, fmt.Errorf("error updating familiarity: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing familiarity: %w", err)
+	}
+
+	return familiarity, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// RecordGroupAnswer records a member's answer to a question of a group quiz.
+// Every member answers each question once; it returns false if the member already answered.
+func (s *QuizService) RecordGroupAnswer(ctx context.Context, userID int64, sessionID int, questionID int, answerIndex *int, answerGiven string, isCorrect bool, timeToAnswer *time.Duration) (bool, error) {
+	recorded, err := s.repo.AddAnswer(ctx, &models.QuizAnswer{
+		UserID:         userID,
+		SessionID:      sessionID,
+		QuestionID:     questionID,
+		AnswerIndex:    answerIndex,
+		AnswerGiven:    answerGiven,
+		IsCorrect:      isCorrect,
+		TimeToAnswerMs: milliseconds(timeToAnswer),
+	})
+
+	if err != nil {
+		return false, fmt.Errorf("error recording group answer: %w", err)
+	}
+
+	return recorded, nil
+}
+
+// GetGroupAnswers returns the members' answers to a question of a group quiz in the order they were given
+func (s *QuizService) GetGroupAnswers(ctx context.Context, sessionID int, questionID int) ([]models.GroupAnswer, error) {
+	answers, err := s.repo.GetAnswers(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying group answers: %w", err)
+	}
+
+	var groupAnswers []models.GroupAnswer
+	for _, answer := range answers {
+		if answer.QuestionID == questionID {
+			groupAnswers = append(groupAnswers, models.GroupAnswer{UserID: answer.UserID, IsCorrect: answer.IsCorrect})
+		}
+	}
+
+	return groupAnswers, nil
+}
+
+// GetGroupLeaderboard returns the members' results in a group quiz, best first.
+// Members with the same number of correct answers are ranked by their total time to answer.
+func (s *QuizService) GetGroupLeaderboard(ctx context.Context, sessionID int) ([]models.GroupScore, error) {
+	answers, err := s.repo.GetAnswers(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying group leaderboard: %w", err)
+	}
+
+	// Members are listed in the order they first answered
+	var scores []models.GroupScore
+	positions := make(map[int64]int)
+	for _, answer := range answers {
+		position, exists := positions[answer.UserID]
+		if !exists {
+			position = len(scores)
+			positions[answer.UserID] = position
+			scores = append(scores, models.GroupScore{UserID: answer.UserID})
+		}
+
+		score := &scores[position]
+		score.Answered++
+		if answer.IsCorrect {
+			score.Correct++
+		}
+		if answer.TimeToAnswerMs != nil {
+			score.TotalTimeMs += int64(*answer.TimeToAnswerMs)
+		}
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Correct != scores[j].Correct {
+			return scores[i].Correct > scores[j].Correct
+		}
+		return scores[i].TotalTimeMs < scores[j].TotalTimeMs
+	})
+
+	return scores, nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// GetLeaderboard ranks users by the accuracy of their completed quiz sessions.
+// An empty language ranks sessions of every language, and since limits the ranking
+// to sessions completed after it when set. Users who answered fewer than minQuestions
+// questions or opted out of leaderboards are left out. Group quizzes are not ranked,
+// their sessions don't belong to a single player, and neither are bookmark quizzes,
+// whose questions the player picked.
+func (s *QuizService) GetLeaderboard(ctx context.Context, language string, since *time.Time, minQuestions int, limit int) ([]models.LeaderboardEntry, error) {
+	return s.repo.GetLeaderboard(ctx, language, since, minQuestions, limit)
+}
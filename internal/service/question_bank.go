@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// UpsertQuestion inserts a question or, if a question with the same text hash
+// already exists, updates it in place and enables it again.
+// It returns true if a new question was inserted.
+func (s *QuizService) UpsertQuestion(ctx context.Context, q *models.QuizQuestion) (bool, error) {
+	return s.repo.UpsertQuestion(ctx, q)
+}
+
+// ListQuestions returns all questions, optionally limited to one language
+func (s *QuizService) ListQuestions(ctx context.Context, language string) ([]*models.QuizQuestion, error) {
+	return s.repo.ListQuestions(ctx, language)
+}
+
+// SetQuestionActive enables or disables a question.
+// Disabled questions are no longer picked for new quizzes.
+func (s *QuizService) SetQuestionActive(ctx context.Context, questionID int, active bool) error {
+	return s.repo.SetQuestionActive(ctx, questionID, active)
+}
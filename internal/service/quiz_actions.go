@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// MarkHintUsed records that a hint was shown for the current question of a session.
+// It returns false if a hint was already used for it.
+func (s *QuizService) MarkHintUsed(ctx context.Context, session *models.QuizSession) (bool, error) {
+	if session.HintUsed {
+		return false, nil
+	}
+
+	updated := *session
+	updated.HintUsed = true
+
+	err := s.repo.SaveProgress(ctx, &updated, session.CurrentQuestionIndex, nil)
+	if errors.Is(err, ErrSessionChanged) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error marking hint as used: %w", err)
+	}
+
+	*session = updated
+	return true, nil
+}
+
+// SkipQuestion records the current question of a session as skipped and moves it,
+// with its option order, to the end of the session, so it is asked again last.
+// It returns false if the current question isn't questionID, e.g. because it was already skipped,
+// or if the session moved on or was completed meanwhile.
+func (s *QuizService) SkipQuestion(ctx context.Context, session *models.QuizSession, questionID int, timeToAnswer *time.Duration) (bool, error) {
+	// A skip keeps the session at the same index, so the question is compared too
+	if session.IsComplete() || session.QuestionIDs[session.CurrentQuestionIndex] != questionID {
+		return false, nil
+	}
+
+	answer := &models.QuizAnswer{
+		UserID:         session.UserID,
+		SessionID:      session.ID,
+		QuestionID:     questionID,
+		TimeToAnswerMs: milliseconds(timeToAnswer),
+		Skipped:        true,
+	}
+
+	updated := *session
+	updated.MoveCurrentQuestionToEnd()
+	updated.Skips++
+	updated.HintUsed = false
+	updated.QuestionSentAt = nil
+
+	err := s.repo.SaveProgress(ctx, &updated, session.CurrentQuestionIndex, answer)
+	if errors.Is(err, ErrSessionChanged) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error skipping question: %w", err)
+	}
+
+	*session = updated
+	return true, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// ErrSessionChanged is returned when a quiz session is saved after it moved on to another question
+// or was completed in the meantime, e.g. by an answer to an older question message
+var ErrSessionChanged = errors.New("quiz session changed in the meantime")
+
+// QuizRepository stores everything the QuizService works with.
+// PostgresQuizRepository is used in production; MemoryQuizRepository keeps the data in memory,
+// for tests and for running the bot without a database.
+type QuizRepository interface {
+	QuestionRepository
+	SessionRepository
+	AnswerRepository
+}
+
+// QuestionRepository stores quiz questions
+type QuestionRepository interface {
+	// GetQuestions returns up to limit questions of a language in random order.
+	// Only questions that can be used in quizzes are returned: active ones, and for single-choice
+	// questions only those with a known correct option. An empty question type selects every type.
+	GetQuestions(ctx context.Context, language string, questionType string, limit int) ([]*models.QuizQuestion, error)
+
+	// GetQuestion returns a question, or nil if there is none with the ID
+	GetQuestion(ctx context.Context, questionID int) (*models.QuizQuestion, error)
+
+	// ListQuestions returns all questions ordered by language, category and ID,
+	// optionally limited to one language
+	ListQuestions(ctx context.Context, language string) ([]*models.QuizQuestion, error)
+
+	// UpsertQuestion inserts a question or updates and enables the question with the same text hash.
+	// It sets the question's ID and returns true if the question was inserted.
+	UpsertQuestion(ctx context.Context, q *models.QuizQuestion) (bool, error)
+
+	// SetQuestionActive enables or disables a question
+	SetQuestionActive(ctx context.Context, questionID int, active bool) error
+
+	// GetLanguages returns the languages of the active questions in alphabetical order
+	GetLanguages(ctx context.Context) ([]string, error)
+}
+
+// SessionRepository stores quiz sessions and the polls sent for their questions
+type SessionRepository interface {
+	// CreateSession stores a new session and sets its ID and start time
+	CreateSession(ctx context.Context, session *models.QuizSession) error
+
+	// GetActiveSession returns the newest uncompleted private session of a user, or nil if there is none
+	GetActiveSession(ctx context.Context, userID int64) (*models.QuizSession, error)
+
+	// GetActiveGroupSession returns the newest uncompleted session of a group chat, or nil if there is none
+	GetActiveGroupSession(ctx context.Context, chatID int64) (*models.QuizSession, error)
+
+	// SaveProgress stores the progress of a session and the answer given in it, if any, in one transaction.
+	// questionIndex is the index of the question the progress was made on. If the stored session
+	// is no longer at it, was completed or, after a skip, has another question than the answer's
+	// at that index, nothing is stored and ErrSessionChanged is returned.
+	SaveProgress(ctx context.Context, session *models.QuizSession, questionIndex int, answer *models.QuizAnswer) error
+
+	// GetLeaderboard ranks users by the accuracy of their completed private sessions, best first.
+	// An empty language ranks every language, and since limits the ranking to sessions completed
+	// after it when set. Users with fewer than minQuestions answered questions are left out.
+	GetLeaderboard(ctx context.Context, language string, since *time.Time, minQuestions int, limit int) ([]models.LeaderboardEntry, error)
+
+	// SaveQuizPoll remembers which session question a quiz poll was sent for
+	SaveQuizPoll(ctx context.Context, poll *models.QuizPoll) error
+
+	// GetQuizPoll looks up a quiz poll by its Telegram poll ID, or returns nil if it wasn't sent for a session
+	GetQuizPoll(ctx context.Context, pollID string) (*models.QuizPoll, error)
+}
+
+// AnswerRepository stores the answers given in quiz sessions
+type AnswerRepository interface {
+	// AddAnswer stores an answer and sets its ID and time. It returns false without storing it
+	// if the user already answered the question in the session; skipping a question doesn't count.
+	AddAnswer(ctx context.Context, answer *models.QuizAnswer) (bool, error)
+
+	// GetAnswers returns the answers given in a session in the order they were given
+	GetAnswers(ctx context.Context, sessionID int) ([]*models.QuizAnswer, error)
+
+	// GetUserQuizStats sums up a user's answers in completed sessions by question language
+	GetUserQuizStats(ctx context.Context, userID int64) (map[string]map[string]int, error)
+}
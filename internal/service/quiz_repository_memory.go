@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// MemoryQuizRepository keeps quiz data in memory. Nothing survives a restart, so it is meant
+// for tests and for running the bot without a database during development.
+// Users' names aren't known, so leaderboard entries only carry user IDs.
+type MemoryQuizRepository struct {
+	mutex          sync.Mutex
+	questions      []*models.QuizQuestion // in the order they were added, so by ID
+	sessions       []*models.QuizSession  // in the order they were started, so by ID
+	answers        []*models.QuizAnswer   // in the order they were given
+	polls          map[string]*models.QuizPoll
+	nextQuestionID int
+	nextSessionID  int
+	nextAnswerID   int
+}
+
+// NewMemoryQuizRepository creates an empty MemoryQuizRepository
+func NewMemoryQuizRepository() *MemoryQuizRepository {
+	return &MemoryQuizRepository{
+		polls:          make(map[string]*models.QuizPoll),
+		nextQuestionID: 1,
+		nextSessionID:  1,
+		nextAnswerID:   1,
+	}
+}
+
+// The repository hands out copies, so callers can't change what it stores without saving it
+
+// copyQuestion returns a copy of a question
+func copyQuestion(q *models.QuizQuestion) *models.QuizQuestion {
+	c := *q
+	c.AnswerOptions = append([]string(nil), q.AnswerOptions...)
+	c.CorrectOptionIndexes = append([]int(nil), q.CorrectOptionIndexes...)
+	c.AcceptedAnswers = append([]string(nil), q.AcceptedAnswers...)
+	return &c
+}
+
+// copySession returns a copy of a session
+func copySession(session *models.QuizSession) *models.QuizSession {
+	c := *session
+	c.QuestionIDs = append([]int(nil), session.QuestionIDs...)
+	c.OptionOrders = nil
+	for _, order := range session.OptionOrders {
+		c.OptionOrders = append(c.OptionOrders, append([]int(nil), order...))
+	}
+	if session.DuelID != nil {
+		duelID := *session.DuelID
+		c.DuelID = &duelID
+	}
+	if session.ChatID != nil {
+		chatID := *session.ChatID
+		c.ChatID = &chatID
+	}
+	if session.QuestionSentAt != nil {
+		sentAt := *session.QuestionSentAt
+		c.QuestionSentAt = &sentAt
+	}
+	if session.CompletedAt != nil {
+		completedAt := *session.CompletedAt
+		c.CompletedAt = &completedAt
+	}
+	return &c
+}
+
+// copyAnswer returns a copy of an answer
+func copyAnswer(answer *models.QuizAnswer) *models.QuizAnswer {
+	c := *answer
+	if answer.AnswerIndex != nil {
+		answerIndex := *answer.AnswerIndex
+		c.AnswerIndex = &answerIndex
+	}
+	if answer.TimeToAnswerMs != nil {
+		ms := *answer.TimeToAnswerMs
+		c.TimeToAnswerMs = &ms
+	}
+	return &c
+}
+
+// GetQuestions returns random usable questions of a language, optionally of one type
+func (r *MemoryQuizRepository) GetQuestions(ctx context.Context, language string, questionType string, limit int) ([]*models.QuizQuestion, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var questions []*models.QuizQuestion
+	for _, q := range r.questions {
+		if q.Language != language || !q.Active || (questionType != "" && q.Type != questionType) {
+			continue
+		}
+		// Single-choice questions without a known correct option can't be graded
+		if q.Type == models.QuestionTypeSingle && q.CorrectOptionIndex < 0 {
+			continue
+		}
+		questions = append(questions, copyQuestion(q))
+	}
+
+	rand.Shuffle(len(questions), func(i, j int) {
+		questions[i], questions[j] = questions[j], questions[i]
+	})
+
+	if len(questions) > limit {
+		questions = questions[:limit]
+	}
+	return questions, nil
+}
+
+// GetQuestion returns a question by ID
+func (r *MemoryQuizRepository) GetQuestion(ctx context.Context, questionID int) (*models.QuizQuestion, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, q := range r.questions {
+		if q.ID == questionID {
+			return copyQuestion(q), nil
+		}
+	}
+	return nil, nil
+}
+
+// ListQuestions returns all questions, optionally limited to one language
+func (r *MemoryQuizRepository) ListQuestions(ctx context.Context, language string) ([]*models.QuizQuestion, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var questions []*models.QuizQuestion
+	for _, q := range r.questions {
+		if language == "" || q.Language == language {
+			questions = append(questions, copyQuestion(q))
+		}
+	}
+
+	// Questions are stored by ID, so a stable sort keeps questions of a category in ID order
+	sort.SliceStable(questions, func(i, j int) bool {
+		if questions[i].Language != questions[j].Language {
+			return questions[i].Language < questions[j].Language
+		}
+		return questions[i].Category < questions[j].Category
+	})
+	return questions, nil
+}
+
+// UpsertQuestion inserts a question or updates and enables the question with the same text hash
+func (r *MemoryQuizRepository) UpsertQuestion(ctx context.Context, q *models.QuizQuestion) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := copyQuestion(q)
+	stored.Active = true
+	if stored.Type != models.QuestionTypeSingle {
+		stored.CorrectOptionIndex = -1
+	}
+
+	hash := q.TextHash()
+	for i, existing := range r.questions {
+		if existing.TextHash() == hash {
+			stored.ID = existing.ID
+			stored.CreatedAt = existing.CreatedAt
+			r.questions[i] = stored
+			q.ID = stored.ID
+			return false, nil
+		}
+	}
+
+	stored.ID = r.nextQuestionID
+	stored.CreatedAt = time.Now()
+	r.nextQuestionID++
+	r.questions = append(r.questions, stored)
+	q.ID = stored.ID
+	return true, nil
+}
+
+// SetQuestionActive enables or disables a question
+func (r *MemoryQuizRepository) SetQuestionActive(ctx context.Context, questionID int, active bool) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, q := range r.questions {
+		if q.ID == questionID {
+			q.Active = active
+		}
+	}
+	return nil
+}
+
+// GetLanguages returns the languages of the active questions
+func (r *MemoryQuizRepository) GetLanguages(ctx context.Context) ([]string, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	seen := make(map[string]bool)
+	var languages []string
+	for _, q := range r.questions {
+		if q.Active && !seen[q.Language] {
+			seen[q.Language] = true
+			languages = append(languages, q.Language)
+		}
+	}
+
+	sort.Strings(languages)
+	return languages, nil
+}
+
+// CreateSession stores a new quiz session
+func (r *MemoryQuizRepository) CreateSession(ctx context.Context, session *models.QuizSession) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	session.ID = r.nextSessionID
+	session.StartedAt = time.Now()
+	r.nextSessionID++
+	r.sessions = append(r.sessions, copySession(session))
+	return nil
+}
+
+// GetActiveSession returns the newest uncompleted private session of a user
+func (r *MemoryQuizRepository) GetActiveSession(ctx context.Context, userID int64) (*models.QuizSession, error) {
+	return r.findActiveSession(func(session *models.QuizSession) bool {
+		return session.UserID == userID && session.ChatID == nil
+	}), nil
+}
+
+// GetActiveGroupSession returns the newest uncompleted session of a group chat
+func (r *MemoryQuizRepository) GetActiveGroupSession(ctx context.Context, chatID int64) (*models.QuizSession, error) {
+	return r.findActiveSession(func(session *models.QuizSession) bool {
+		return session.ChatID != nil && *session.ChatID == chatID
+	}), nil
+}
+
+// findActiveSession returns a copy of the newest uncompleted session that matches, or nil
+func (r *MemoryQuizRepository) findActiveSession(matches func(session *models.QuizSession) bool) *models.QuizSession {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := len(r.sessions) - 1; i >= 0; i-- {
+		if session := r.sessions[i]; session.CompletedAt == nil && matches(session) {
+			return copySession(session)
+		}
+	}
+	return nil
+}
+
+// SaveProgress stores the progress of a session with the answer given in it, if any
+func (r *MemoryQuizRepository) SaveProgress(ctx context.Context, session *models.QuizSession, questionIndex int, answer *models.QuizAnswer) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, stored := range r.sessions {
+		if stored.ID != session.ID {
+			continue
+		}
+
+		if stored.CurrentQuestionIndex != questionIndex || stored.CompletedAt != nil {
+			return ErrSessionChanged
+		}
+
+		if answer != nil && (questionIndex >= len(stored.QuestionIDs) || stored.QuestionIDs[questionIndex] != answer.QuestionID) {
+			return ErrSessionChanged
+		}
+
+		if answer != nil && !r.addAnswer(answer) {
+			return ErrSessionChanged
+		}
+
+		// The settings chosen when the session started can't change
+		updated := copySession(session)
+		updated.UserID = stored.UserID
+		updated.Language = stored.Language
+		updated.Timed = stored.Timed
+		updated.RenderMode = stored.RenderMode
+		updated.DuelID = stored.DuelID
+		updated.ChatID = stored.ChatID
+		updated.StartedAt = stored.StartedAt
+		r.sessions[i] = updated
+		return nil
+	}
+
+	return ErrSessionChanged
+}
+
+// GetLeaderboard ranks users by the accuracy of their completed private sessions.
+// Like in the database, bookmark quizzes are not ranked.
+func (r *MemoryQuizRepository) GetLeaderboard(ctx context.Context, language string, since *time.Time, minQuestions int, limit int) ([]models.LeaderboardEntry, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	totals := make(map[int64]*models.LeaderboardEntry)
+	var entries []*models.LeaderboardEntry
+	for _, session := range r.sessions {
+		if session.CompletedAt == nil || session.IsGroup() || session.Language == models.BookmarksLanguage ||
+			(language != "" && session.Language != language) || (since != nil && session.CompletedAt.Before(*since)) {
+			continue
+		}
+
+		entry, exists := totals[session.UserID]
+		if !exists {
+			entry = &models.LeaderboardEntry{User: models.User{ID: session.UserID}}
+			totals[session.UserID] = entry
+			entries = append(entries, entry)
+		}
+		entry.Correct += session.CorrectAnswers
+		entry.HintedAnswers += session.HintedAnswers
+		entry.Questions += session.CurrentQuestionIndex
+	}
+
+	var leaderboard []models.LeaderboardEntry
+	for _, entry := range entries {
+		if entry.Questions >= minQuestions {
+			leaderboard = append(leaderboard, *entry)
+		}
+	}
+
+	sort.SliceStable(leaderboard, func(i, j int) bool {
+		if leaderboard[i].Accuracy() != leaderboard[j].Accuracy() {
+			return leaderboard[i].Accuracy() > leaderboard[j].Accuracy()
+		}
+		return leaderboard[i].Correct > leaderboard[j].Correct
+	})
+
+	if len(leaderboard) > limit {
+		leaderboard = leaderboard[:limit]
+	}
+	return leaderboard, nil
+}
+
+// SaveQuizPoll remembers which session question a quiz poll was sent for
+func (r *MemoryQuizRepository) SaveQuizPoll(ctx context.Context, poll *models.QuizPoll) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *poll
+	stored.CreatedAt = time.Now()
+	r.polls[poll.PollID] = &stored
+	return nil
+}
+
+// GetQuizPoll looks up a quiz poll by its Telegram poll ID
+func (r *MemoryQuizRepository) GetQuizPoll(ctx context.Context, pollID string) (*models.QuizPoll, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	poll, exists := r.polls[pollID]
+	if !exists {
+		return nil, nil
+	}
+
+	found := *poll
+	return &found, nil
+}
+
+// AddAnswer stores an answer unless the user already answered its question in the session
+func (r *MemoryQuizRepository) AddAnswer(ctx context.Context, answer *models.QuizAnswer) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.addAnswer(answer), nil
+}
+
+// addAnswer stores an answer unless it repeats an earlier one. The caller must hold the mutex.
+func (r *MemoryQuizRepository) addAnswer(answer *models.QuizAnswer) bool {
+	// A skipped question comes back later in the session and is answered then
+	if !answer.Skipped {
+		for _, existing := range r.answers {
+			if existing.SessionID == answer.SessionID && existing.QuestionID == answer.QuestionID &&
+				existing.UserID == answer.UserID && !existing.Skipped {
+				return false
+			}
+		}
+	}
+
+	answer.ID = r.nextAnswerID
+	answer.AnsweredAt = time.Now()
+	r.nextAnswerID++
+	r.answers = append(r.answers, copyAnswer(answer))
+	return true
+}
+
+// GetAnswers returns the answers given in a session in the order they were given
+func (r *MemoryQuizRepository) GetAnswers(ctx context.Context, sessionID int) ([]*models.QuizAnswer, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var answers []*models.QuizAnswer
+	for _, answer := range r.answers {
+		if answer.SessionID == sessionID {
+			answers = append(answers, copyAnswer(answer))
+		}
+	}
+	return answers, nil
+}
+
+// GetUserQuizStats sums up a user's answers in completed sessions by question language
+func (r *MemoryQuizRepository) GetUserQuizStats(ctx context.Context, userID int64) (map[string]map[string]int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	completed := make(map[int]bool)
+	for _, session := range r.sessions {
+		if session.CompletedAt != nil {
+			completed[session.ID] = true
+		}
+	}
+
+	languages := make(map[int]string)
+	for _, q := range r.questions {
+		languages[q.ID] = q.Language
+	}
+
+	type languageTotals struct {
+		sessions             map[int]bool
+		questions, correct   int
+		timedAnswers, timeMs int
+	}
+
+	totals := make(map[string]*languageTotals)
+	for _, answer := range r.answers {
+		language, known := languages[answer.QuestionID]
+		if answer.UserID != userID || answer.Skipped || !completed[answer.SessionID] || !known {
+			continue
+		}
+
+		t, exists := totals[language]
+		if !exists {
+			t = &languageTotals{sessions: make(map[int]bool)}
+			totals[language] = t
+		}
+
+		t.sessions[answer.SessionID] = true
+		t.questions++
+		if answer.IsCorrect {
+			t.correct++
+		}
+		if answer.TimeToAnswerMs != nil {
+			t.timedAnswers++
+			t.timeMs += *answer.TimeToAnswerMs
+		}
+	}
+
+	stats := make(map[string]map[string]int)
+	for language, t := range totals {
+		averageTimeMs := 0
+		if t.timedAnswers > 0 {
+			averageTimeMs = t.timeMs / t.timedAnswers
+		}
+
+		stats[language] = map[string]int{
+			"completed_quizzes":     len(t.sessions),
+			"total_questions":       t.questions,
+			"correct_answers":       t.correct,
+			"avg_time_to_answer_ms": averageTimeMs,
+		}
+	}
+	return stats, nil
+}
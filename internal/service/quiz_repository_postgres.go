@@ -0,0 +1,703 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// PostgresQuizRepository stores quiz data in PostgreSQL
+type PostgresQuizRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresQuizRepository creates a new PostgresQuizRepository
+func NewPostgresQuizRepository(db *sql.DB) *PostgresQuizRepository {
+	return &PostgresQuizRepository{db: db}
+}
+
+// questionColumns lists the quiz_questions columns read by scanQuestion
+const questionColumns = `id, language, category, difficulty, type, question_text, answer_options, correct_answer,
+	COALESCE(correct_option_index, -1), correct_option_indexes, accepted_answers, explanation, hint, active, created_at`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanQuestion reads a question selected with questionColumns
+func scanQuestion(row rowScanner) (*models.QuizQuestion, error) {
+	q := &models.QuizQuestion{}
+	var answerOptionsJSON string
+	var correctOptionIndexesJSON, acceptedAnswersJSON sql.NullString
+
+	err := row.Scan(
+		&q.ID,
+		&q.Language,
+		&q.Category,
+		&q.Difficulty,
+		&q.Type,
+		&q.QuestionText,
+		&answerOptionsJSON,
+		&q.CorrectAnswer,
+		&q.CorrectOptionIndex,
+		&correctOptionIndexesJSON,
+		&acceptedAnswersJSON,
+		&q.Explanation,
+		&q.Hint,
+		&q.Active,
+		&q.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the JSON array of answer options
+	err = json.Unmarshal([]byte(answerOptionsJSON), &q.AnswerOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling answer options: %w", err)
+	}
+
+	if correctOptionIndexesJSON.Valid {
+		err = json.Unmarshal([]byte(correctOptionIndexesJSON.String), &q.CorrectOptionIndexes)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling correct option indexes: %w", err)
+		}
+	}
+
+	if acceptedAnswersJSON.Valid {
+		err = json.Unmarshal([]byte(acceptedAnswersJSON.String), &q.AcceptedAnswers)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling accepted answers: %w", err)
+		}
+	}
+
+	return q, nil
+}
+
+// GetQuestions retrieves random usable questions of a language, optionally of one type
+func (r *PostgresQuizRepository) GetQuestions(ctx context.Context, language string, questionType string, limit int) ([]*models.QuizQuestion, error) {
+	// Disabled questions and single-choice questions without a known correct option are skipped
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM quiz_questions
+		WHERE language = $1 AND active AND (type <> 'single' OR correct_option_index IS NOT NULL)
+			AND ($3 = '' OR type = $3)
+		ORDER BY RANDOM()
+		LIMIT $2
+	`, language, limit, questionType)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying questions: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []*models.QuizQuestion
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning question row: %w", err)
+		}
+
+		questions = append(questions, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating question rows: %w", err)
+	}
+
+	return questions, nil
+}
+
+// GetQuestion retrieves a specific question by ID
+func (r *PostgresQuizRepository) GetQuestion(ctx context.Context, questionID int) (*models.QuizQuestion, error) {
+	q, err := scanQuestion(r.db.QueryRowContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM quiz_questions
+		WHERE id = $1
+	`, questionID))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error querying question: %w", err)
+	}
+
+	return q, nil
+}
+
+// ListQuestions returns all questions, optionally limited to one language
+func (r *PostgresQuizRepository) ListQuestions(ctx context.Context, language string) ([]*models.QuizQuestion, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+questionColumns+`
+		FROM quiz_questions
+		WHERE $1 = '' OR language = $1
+		ORDER BY language, category, id
+	`, language)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying questions: %w", err)
+	}
+	defer rows.Close()
+
+	var questions []*models.QuizQuestion
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning question row: %w", err)
+		}
+
+		questions = append(questions, q)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating question rows: %w", err)
+	}
+
+	return questions, nil
+}
+
+// UpsertQuestion inserts a question or, if a question with the same text hash
+// already exists, updates it in place and enables it again
+func (r *PostgresQuizRepository) UpsertQuestion(ctx context.Context, q *models.QuizQuestion) (bool, error) {
+	answerOptionsJSON, err := json.Marshal(q.AnswerOptions)
+	if err != nil {
+		return false, fmt.Errorf("error marshaling answer options: %w", err)
+	}
+
+	// Store NULL rather than an empty list for type-specific fields
+	var correctOptionIndexesJSON, acceptedAnswersJSON []byte
+	if len(q.CorrectOptionIndexes) > 0 {
+		if correctOptionIndexesJSON, err = json.Marshal(q.CorrectOptionIndexes); err != nil {
+			return false, fmt.Errorf("error marshaling correct option indexes: %w", err)
+		}
+	}
+	if len(q.AcceptedAnswers) > 0 {
+		if acceptedAnswersJSON, err = json.Marshal(q.AcceptedAnswers); err != nil {
+			return false, fmt.Errorf("error marshaling accepted answers: %w", err)
+		}
+	}
+
+	var correctOptionIndex sql.NullInt64
+	if q.Type == models.QuestionTypeSingle {
+		correctOptionIndex = sql.NullInt64{Int64: int64(q.CorrectOptionIndex), Valid: true}
+	}
+
+	// xmax is zero only for freshly inserted rows
+	var inserted bool
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO quiz_questions (language, category, difficulty, type, question_text, answer_options,
+			correct_answer, correct_option_index, correct_option_indexes, accepted_answers, explanation, hint, text_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (text_hash) DO UPDATE SET
+			category = EXCLUDED.category,
+			difficulty = EXCLUDED.difficulty,
+			type = EXCLUDED.type,
+			question_text = EXCLUDED.question_text,
+			answer_options = EXCLUDED.answer_options,
+			correct_answer = EXCLUDED.correct_answer,
+			correct_option_index = EXCLUDED.correct_option_index,
+			correct_option_indexes = EXCLUDED.correct_option_indexes,
+			accepted_answers = EXCLUDED.accepted_answers,
+			explanation = EXCLUDED.explanation,
+			hint = EXCLUDED.hint,
+			active = TRUE
+		RETURNING id, (xmax = 0)
+	`, q.Language, q.Category, q.Difficulty, q.Type, q.QuestionText, answerOptionsJSON,
+		q.CorrectAnswer, correctOptionIndex, nullableJSON(correctOptionIndexesJSON), nullableJSON(acceptedAnswersJSON),
+		q.Explanation, q.Hint, q.TextHash()).Scan(&q.ID, &inserted)
+
+	if err != nil {
+		return false, fmt.Errorf("error upserting question: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// SetQuestionActive enables or disables a question
+func (r *PostgresQuizRepository) SetQuestionActive(ctx context.Context, questionID int, active bool) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE quiz_questions
+		SET active = $2
+		WHERE id = $1
+	`, questionID, active)
+
+	if err != nil {
+		return fmt.Errorf("error updating question %d: %w", questionID, err)
+	}
+
+	return nil
+}
+
+// GetLanguages returns the languages of the active questions
+func (r *PostgresQuizRepository) GetLanguages(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT language
+		FROM quiz_questions
+		WHERE active
+		ORDER BY language
+	`)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying languages: %w", err)
+	}
+	defer rows.Close()
+
+	var languages []string
+	for rows.Next() {
+		var language string
+		if err := rows.Scan(&language); err != nil {
+			return nil, fmt.Errorf("error scanning language: %w", err)
+		}
+		languages = append(languages, language)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating language rows: %w", err)
+	}
+
+	return languages, nil
+}
+
+// CreateSession stores a new quiz session
+func (r *PostgresQuizRepository) CreateSession(ctx context.Context, session *models.QuizSession) error {
+	// Convert question IDs and option orders to JSON
+	questionIDsJSON, err := json.Marshal(session.QuestionIDs)
+	if err != nil {
+		return fmt.Errorf("error marshaling question IDs: %w", err)
+	}
+
+	optionOrdersJSON, err := json.Marshal(session.OptionOrders)
+	if err != nil {
+		return fmt.Errorf("error marshaling option orders: %w", err)
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO user_quiz_sessions (user_id, language, question_ids, option_orders, timed, render_mode, duel_id, chat_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, started_at
+	`, session.UserID, session.Language, questionIDsJSON, optionOrdersJSON, session.Timed, session.RenderMode,
+		session.DuelID, session.ChatID).Scan(&session.ID, &session.StartedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating quiz session: %w", err)
+	}
+
+	return nil
+}
+
+// sessionColumns lists the quiz session columns read by scanSession
+const sessionColumns = `id, user_id, language, current_question_index, question_ids, option_orders, correct_answers,
+	hint_used, hinted_answers, skips, timed, render_mode, duel_id, chat_id, question_sent_at, started_at, completed_at`
+
+// scanSession reads a quiz session selected with sessionColumns
+func scanSession(row rowScanner) (*models.QuizSession, error) {
+	var session models.QuizSession
+	var questionIDsJSON string
+	var optionOrdersJSON sql.NullString
+	var duelID sql.NullInt64
+	var chatID sql.NullInt64
+	var questionSentAt sql.NullTime
+	var completedAt sql.NullTime
+
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Language,
+		&session.CurrentQuestionIndex,
+		&questionIDsJSON,
+		&optionOrdersJSON,
+		&session.CorrectAnswers,
+		&session.HintUsed,
+		&session.HintedAnswers,
+		&session.Skips,
+		&session.Timed,
+		&session.RenderMode,
+		&duelID,
+		&chatID,
+		&questionSentAt,
+		&session.StartedAt,
+		&completedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the JSON array of question IDs
+	err = json.Unmarshal([]byte(questionIDsJSON), &session.QuestionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshaling question IDs: %w", err)
+	}
+
+	// Sessions created before options were shuffled have no option orders
+	if optionOrdersJSON.Valid {
+		err = json.Unmarshal([]byte(optionOrdersJSON.String), &session.OptionOrders)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling option orders: %w", err)
+		}
+	}
+
+	if duelID.Valid {
+		id := int(duelID.Int64)
+		session.DuelID = &id
+	}
+
+	if chatID.Valid {
+		session.ChatID = &chatID.Int64
+	}
+
+	if questionSentAt.Valid {
+		session.QuestionSentAt = &questionSentAt.Time
+	}
+
+	if completedAt.Valid {
+		session.CompletedAt = &completedAt.Time
+	}
+
+	return &session, nil
+}
+
+// GetActiveSession retrieves the active private quiz session of a user.
+// Group quizzes the user started are not included.
+func (r *PostgresQuizRepository) GetActiveSession(ctx context.Context, userID int64) (*models.QuizSession, error) {
+	session, err := scanSession(r.db.QueryRowContext(ctx, `
+		SELECT `+sessionColumns+`
+		FROM user_quiz_sessions
+		WHERE user_id = $1 AND chat_id IS NULL AND completed_at IS NULL
+		ORDER BY started_at DESC
+		LIMIT 1
+	`, userID))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // No active session
+		}
+		return nil, fmt.Errorf("error querying active session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetActiveGroupSession retrieves the active quiz session of a group chat
+func (r *PostgresQuizRepository) GetActiveGroupSession(ctx context.Context, chatID int64) (*models.QuizSession, error) {
+	session, err := scanSession(r.db.QueryRowContext(ctx, `
+		SELECT `+sessionColumns+`
+		FROM user_quiz_sessions
+		WHERE chat_id = $1 AND completed_at IS NULL
+		ORDER BY started_at DESC
+		LIMIT 1
+	`, chatID))
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil // No active session
+		}
+		return nil, fmt.Errorf("error querying active group session: %w", err)
+	}
+
+	return session, nil
+}
+
+// SaveProgress stores the progress of a session with the answer given in it, if any
+func (r *PostgresQuizRepository) SaveProgress(ctx context.Context, session *models.QuizSession, questionIndex int, answer *models.QuizAnswer) error {
+	questionIDsJSON, err := json.Marshal(session.QuestionIDs)
+	if err != nil {
+		return fmt.Errorf("error marshaling question IDs: %w", err)
+	}
+
+	// Sessions created before options were shuffled keep having no option orders
+	var optionOrdersJSON []byte
+	if len(session.OptionOrders) > 0 {
+		if optionOrdersJSON, err = json.Marshal(session.OptionOrders); err != nil {
+			return fmt.Errorf("error marshaling option orders: %w", err)
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A skip keeps the session at the same index, so answers also check the question at it
+	var answerQuestionID *int
+	if answer != nil {
+		answerQuestionID = &answer.QuestionID
+	}
+
+	// The completion time is the database's, like the start time.
+	// The update locks the session row, so of two saves made at the same question only the first
+	// goes through; the second finds the session moved on once the first commits.
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_quiz_sessions
+		SET current_question_index = $3, question_ids = $4, option_orders = $5, correct_answers = $6,
+			hint_used = $7, hinted_answers = $8, skips = $9, question_sent_at = $10,
+			completed_at = CASE WHEN $11::BOOLEAN THEN NOW() END
+		WHERE id = $1 AND current_question_index = $2 AND completed_at IS NULL
+			AND ($12::INT IS NULL OR (question_ids->>$2::INT)::INT = $12)
+	`, session.ID, questionIndex, session.CurrentQuestionIndex, questionIDsJSON, nullableJSON(optionOrdersJSON),
+		session.CorrectAnswers, session.HintUsed, session.HintedAnswers, session.Skips, session.QuestionSentAt,
+		session.CompletedAt != nil, answerQuestionID)
+
+	if err != nil {
+		return fmt.Errorf("error saving quiz session: %w", err)
+	}
+
+	saved, err := rowsAffected(result)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		return ErrSessionChanged
+	}
+
+	if answer != nil {
+		added, err := addAnswer(ctx, tx, answer)
+		if err != nil {
+			return err
+		}
+		if !added {
+			return ErrSessionChanged
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing quiz session: %w", err)
+	}
+
+	return nil
+}
+
+// rowQuerier is implemented by *sql.DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// addAnswer inserts an answer unless the user already answered its question in the session
+func addAnswer(ctx context.Context, db rowQuerier, answer *models.QuizAnswer) (bool, error) {
+	var answerIndex sql.NullInt64
+	if answer.AnswerIndex != nil {
+		answerIndex = sql.NullInt64{Int64: int64(*answer.AnswerIndex), Valid: true}
+	}
+
+	var timeToAnswerMs sql.NullInt64
+	if answer.TimeToAnswerMs != nil {
+		timeToAnswerMs = sql.NullInt64{Int64: int64(*answer.TimeToAnswerMs), Valid: true}
+	}
+
+	// A skipped question comes back later in the session and is answered then,
+	// so only answers that aren't skips are unique
+	err := db.QueryRowContext(ctx, `
+		INSERT INTO user_quiz_answers (user_id, session_id, question_id, answer_index, answer_given, is_correct,
+			time_to_answer_ms, used_hint, skipped)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (session_id, question_id, user_id) WHERE NOT skipped DO NOTHING
+		RETURNING id, answered_at
+	`, answer.UserID, answer.SessionID, answer.QuestionID, answerIndex, answer.AnswerGiven, answer.IsCorrect,
+		timeToAnswerMs, answer.UsedHint, answer.Skipped).Scan(&answer.ID, &answer.AnsweredAt)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error recording answer: %w", err)
+	}
+
+	return true, nil
+}
+
+// GetLeaderboard ranks users by the accuracy of their completed quiz sessions,
+// with the hint penalty taken off like in QuizSession.GetScore. Users who opted out of leaderboards are left out. Group quizzes are not ranked,
+// their sessions don't belong to a single player, and neither are bookmark quizzes,
+// whose questions the player picked.
+func (r *PostgresQuizRepository) GetLeaderboard(ctx context.Context, language string, since *time.Time, minQuestions int, limit int) ([]models.LeaderboardEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.user_id, COALESCE(p.username, ''), COALESCE(p.first_name, ''), COALESCE(p.last_name, ''),
+			SUM(s.correct_answers) AS correct, SUM(s.hinted_answers) AS hinted, SUM(s.current_question_index) AS questions
+		FROM user_quiz_sessions s
+		LEFT JOIN user_profiles p ON p.user_id = s.user_id
+		WHERE s.completed_at IS NOT NULL AND s.chat_id IS NULL AND s.language <> $5
+			AND ($1 = '' OR s.language = $1)
+			AND ($2::TIMESTAMP IS NULL OR s.completed_at >= $2)
+			AND NOT COALESCE(p.leaderboard_opt_out, FALSE)
+		GROUP BY s.user_id, p.username, p.first_name, p.last_name
+		HAVING SUM(s.current_question_index) >= $3
+		ORDER BY (SUM(s.correct_answers) - $6::FLOAT * SUM(s.hinted_answers)) / NULLIF(SUM(s.current_question_index), 0) DESC NULLS LAST,
+			correct DESC
+		LIMIT $4
+	`, language, since, minQuestions, limit, models.BookmarksLanguage, models.HintPenalty)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.LeaderboardEntry
+	for rows.Next() {
+		var entry models.LeaderboardEntry
+		err := rows.Scan(&entry.User.ID, &entry.User.Username, &entry.User.FirstName, &entry.User.LastName,
+			&entry.Correct, &entry.HintedAnswers, &entry.Questions)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning leaderboard row: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating leaderboard rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// SaveQuizPoll remembers which session question a quiz poll was sent for
+func (r *PostgresQuizRepository) SaveQuizPoll(ctx context.Context, poll *models.QuizPoll) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO quiz_polls (poll_id, session_id, question_index, chat_id, message_id)
+		VALUES ($1, $2, $3, $4, $5)
+	`, poll.PollID, poll.SessionID, poll.QuestionIndex, poll.ChatID, poll.MessageID)
+
+	if err != nil {
+		return fmt.Errorf("error saving quiz poll: %w", err)
+	}
+
+	return nil
+}
+
+// GetQuizPoll looks up a quiz poll by its Telegram poll ID
+func (r *PostgresQuizRepository) GetQuizPoll(ctx context.Context, pollID string) (*models.QuizPoll, error) {
+	var poll models.QuizPoll
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT poll_id, session_id, question_index, chat_id, message_id, created_at
+		FROM quiz_polls
+		WHERE poll_id = $1
+	`, pollID).Scan(
+		&poll.PollID,
+		&poll.SessionID,
+		&poll.QuestionIndex,
+		&poll.ChatID,
+		&poll.MessageID,
+		&poll.CreatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error querying quiz poll: %w", err)
+	}
+
+	return &poll, nil
+}
+
+// AddAnswer stores an answer unless the user already answered its question in the session
+func (r *PostgresQuizRepository) AddAnswer(ctx context.Context, answer *models.QuizAnswer) (bool, error) {
+	return addAnswer(ctx, r.db, answer)
+}
+
+// GetAnswers returns the answers given in a session in the order they were given
+func (r *PostgresQuizRepository) GetAnswers(ctx context.Context, sessionID int) ([]*models.QuizAnswer, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, user_id, session_id, question_id, answer_index, answer_given, is_correct,
+			time_to_answer_ms, used_hint, skipped, answered_at
+		FROM user_quiz_answers
+		WHERE session_id = $1
+		ORDER BY answered_at, id
+	`, sessionID)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying answers: %w", err)
+	}
+	defer rows.Close()
+
+	var answers []*models.QuizAnswer
+	for rows.Next() {
+		var answer models.QuizAnswer
+		var answerIndex, timeToAnswerMs sql.NullInt64
+
+		err := rows.Scan(&answer.ID, &answer.UserID, &answer.SessionID, &answer.QuestionID, &answerIndex,
+			&answer.AnswerGiven, &answer.IsCorrect, &timeToAnswerMs, &answer.UsedHint, &answer.Skipped, &answer.AnsweredAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning answer: %w", err)
+		}
+
+		if answerIndex.Valid {
+			index := int(answerIndex.Int64)
+			answer.AnswerIndex = &index
+		}
+		if timeToAnswerMs.Valid {
+			ms := int(timeToAnswerMs.Int64)
+			answer.TimeToAnswerMs = &ms
+		}
+
+		answers = append(answers, &answer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating answers: %w", err)
+	}
+
+	return answers, nil
+}
+
+// GetUserQuizStats gets statistics about a user's quiz performance
+func (r *PostgresQuizRepository) GetUserQuizStats(ctx context.Context, userID int64) (map[string]map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			q.language,
+			COUNT(DISTINCT s.id) AS completed_quizzes,
+			COUNT(a.id) AS total_questions,
+			SUM(CASE WHEN a.is_correct THEN 1 ELSE 0 END) AS correct_answers,
+			COALESCE(AVG(a.time_to_answer_ms), 0)::INT AS avg_time_to_answer_ms
+		FROM user_quiz_sessions s
+		JOIN user_quiz_answers a ON s.id = a.session_id
+		JOIN quiz_questions q ON a.question_id = q.id
+		WHERE a.user_id = $1 AND NOT a.skipped AND s.completed_at IS NOT NULL
+		GROUP BY q.language
+	`, userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("error querying user stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]map[string]int)
+	for rows.Next() {
+		var language string
+		var completedQuizzes, totalQuestions, correctAnswers, avgTimeToAnswerMs int
+
+		err := rows.Scan(&language, &completedQuizzes, &totalQuestions, &correctAnswers, &avgTimeToAnswerMs)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning stats row: %w", err)
+		}
+
+		stats[language] = map[string]int{
+			"completed_quizzes":     completedQuizzes,
+			"total_questions":       totalQuestions,
+			"correct_answers":       correctAnswers,
+			"avg_time_to_answer_ms": avgTimeToAnswerMs,
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stats rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// nullableJSON turns an empty JSON document into a SQL NULL
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -1,8 +1,7 @@
 package service
 
 import (
-	"database/sql"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -10,307 +9,207 @@ import (
 	"github.com/amiosamu/interview-match-bot/internal/models"
 )
 
-// QuizService handles quiz-related operations
+// QuizService handles quiz-related operations.
+// It applies the quiz rules, such as scoring and advancing sessions, and leaves storing the
+// questions, sessions and answers to a QuizRepository.
 type QuizService struct {
-	db *sql.DB
+	repo QuizRepository
 }
 
 // NewQuizService creates a new QuizService
-func NewQuizService(db *sql.DB) *QuizService {
-	return &QuizService{db: db}
+func NewQuizService(repo QuizRepository) *QuizService {
+	return &QuizService{repo: repo}
 }
 
 // GetQuestionsByLanguage retrieves random questions for a specific language
-func (s *QuizService) GetQuestionsByLanguage(language string, limit int) ([]*models.QuizQuestion, error) {
-	// Query randomly selected questions for the specified language
-	rows, err := s.db.Query(`
-		SELECT id, language, category, difficulty, question_text, answer_options, correct_answer, explanation, created_at 
-		FROM quiz_questions 
-		WHERE language = $1 
-		ORDER BY RANDOM() 
-		LIMIT $2
-	`, language, limit)
-	
-	if err != nil {
-		return nil, fmt.Errorf("error querying questions: %w", err)
-	}
-	defer rows.Close()
-	
-	var questions []*models.QuizQuestion
-	for rows.Next() {
-		q := &models.QuizQuestion{}
-		var answerOptionsJSON string
-		
-		err := rows.Scan(
-			&q.ID, 
-			&q.Language, 
-			&q.Category, 
-			&q.Difficulty, 
-			&q.QuestionText, 
-			&answerOptionsJSON, 
-			&q.CorrectAnswer, 
-			&q.Explanation,
-			&q.CreatedAt,
-		)
-		
-		if err != nil {
-			return nil, fmt.Errorf("error scanning question row: %w", err)
-		}
-		
-		// Parse the JSON array of answer options
-		err = json.Unmarshal([]byte(answerOptionsJSON), &q.AnswerOptions)
-		if err != nil {
-			return nil, fmt.Errorf("error unmarshaling answer options: %w", err)
-		}
-		
-		questions = append(questions, q)
-	}
-	
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating question rows: %w", err)
-	}
-	
-	return questions, nil
+func (s *QuizService) GetQuestionsByLanguage(ctx context.Context, language string, limit int) ([]*models.QuizQuestion, error) {
+	return s.GetQuestionsByType(ctx, language, "", limit)
+}
+
+// GetQuestionsByType retrieves random questions of one type for a specific language.
+// An empty question type selects questions of every type.
+// Disabled questions and single-choice questions without a known correct option are skipped.
+func (s *QuizService) GetQuestionsByType(ctx context.Context, language string, questionType string, limit int) ([]*models.QuizQuestion, error) {
+	return s.repo.GetQuestions(ctx, language, questionType, limit)
 }
 
 // GetQuestionByID retrieves a specific question by ID
-func (s *QuizService) GetQuestionByID(questionID int) (*models.QuizQuestion, error) {
-	q := &models.QuizQuestion{}
-	var answerOptionsJSON string
-	
-	err := s.db.QueryRow(`
-		SELECT id, language, category, difficulty, question_text, answer_options, correct_answer, explanation, created_at 
-		FROM quiz_questions 
-		WHERE id = $1
-	`, questionID).Scan(
-		&q.ID, 
-		&q.Language, 
-		&q.Category, 
-		&q.Difficulty, 
-		&q.QuestionText, 
-		&answerOptionsJSON, 
-		&q.CorrectAnswer, 
-		&q.Explanation,
-		&q.CreatedAt,
-	)
-	
+func (s *QuizService) GetQuestionByID(ctx context.Context, questionID int) (*models.QuizQuestion, error) {
+	q, err := s.repo.GetQuestion(ctx, questionID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("question not found: %w", err)
-		}
-		return nil, fmt.Errorf("error querying question: %w", err)
+		return nil, err
 	}
-	
-	// Parse the JSON array of answer options
-	err = json.Unmarshal([]byte(answerOptionsJSON), &q.AnswerOptions)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling answer options: %w", err)
+
+	if q == nil {
+		return nil, fmt.Errorf("question %d not found", questionID)
 	}
-	
+
 	return q, nil
 }
 
-// CreateQuizSession starts a new quiz session for a user
-func (s *QuizService) CreateQuizSession(userID int64, language string, questionIDs []int) (*models.QuizSession, error) {
-	// Convert question IDs to JSON
-	questionIDsJSON, err := json.Marshal(questionIDs)
-	if err != nil {
-		return nil, fmt.Errorf("error marshaling question IDs: %w", err)
+// CreateQuizSession starts a new quiz session for a user.
+// The answer options of every question are shuffled once per session.
+func (s *QuizService) CreateQuizSession(ctx context.Context, userID int64, language string, questions []*models.QuizQuestion, options models.QuizOptions) (*models.QuizSession, error) {
+	if options.RenderMode == "" {
+		options.RenderMode = models.RenderModeKeyboard
 	}
-	
-	// Create the session in the database
-	var sessionID int
-	err = s.db.QueryRow(`
-		INSERT INTO user_quiz_sessions (user_id, language, question_ids)
-		VALUES ($1, $2, $3)
-		RETURNING id, started_at
-	`, userID, language, questionIDsJSON).Scan(&sessionID, &time.Time{})
-	
-	if err != nil {
-		return nil, fmt.Errorf("error creating quiz session: %w", err)
+
+	session := &models.QuizSession{
+		UserID:       userID,
+		Language:     language,
+		QuestionIDs:  make([]int, 0, len(questions)),
+		OptionOrders: make([][]int, 0, len(questions)),
+		Timed:        options.Timed,
+		RenderMode:   options.RenderMode,
+		DuelID:       options.DuelID,
+		ChatID:       options.ChatID,
 	}
-	
-	// Return the new session
-	return &models.QuizSession{
-		ID:                  sessionID,
-		UserID:              userID,
-		Language:            language,
-		CurrentQuestionIndex: 0,
-		QuestionIDs:         questionIDs,
-		CorrectAnswers:      0,
-		StartedAt:           time.Now(),
-	}, nil
-}
 
-// GetActiveQuizSession retrieves the active quiz session for a user
-func (s *QuizService) GetActiveQuizSession(userID int64) (*models.QuizSession, error) {
-	var session models.QuizSession
-	var questionIDsJSON string
-	var completedAt sql.NullTime
-	
-	err := s.db.QueryRow(`
-		SELECT id, user_id, language, current_question_index, question_ids, correct_answers, started_at, completed_at
-		FROM user_quiz_sessions
-		WHERE user_id = $1 AND completed_at IS NULL
-		ORDER BY started_at DESC
-		LIMIT 1
-	`, userID).Scan(
-		&session.ID,
-		&session.UserID,
-		&session.Language,
-		&session.CurrentQuestionIndex,
-		&questionIDsJSON,
-		&session.CorrectAnswers,
-		&session.StartedAt,
-		&completedAt,
-	)
-	
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil // No active session
-		}
-		return nil, fmt.Errorf("error querying active session: %w", err)
+	for _, q := range questions {
+		session.QuestionIDs = append(session.QuestionIDs, q.ID)
+		session.OptionOrders = append(session.OptionOrders, models.ShuffledOptionOrder(len(q.AnswerOptions)))
 	}
-	
-	// Parse the JSON array of question IDs
-	err = json.Unmarshal([]byte(questionIDsJSON), &session.QuestionIDs)
-	if err != nil {
-		return nil, fmt.Errorf("error unmarshaling question IDs: %w", err)
+
+	if err := s.repo.CreateSession(ctx, session); err != nil {
+		return nil, err
 	}
-	
-	if completedAt.Valid {
-		session.CompletedAt = &completedAt.Time
+
+	return session, nil
+}
+
+// GetActiveQuizSession retrieves the active quiz session for a user.
+// Group quizzes the user started are not included.
+func (s *QuizService) GetActiveQuizSession(ctx context.Context, userID int64) (*models.QuizSession, error) {
+	return s.repo.GetActiveSession(ctx, userID)
+}
+
+// GetActiveGroupQuizSession retrieves the active quiz session of a group chat
+func (s *QuizService) GetActiveGroupQuizSession(ctx context.Context, chatID int64) (*models.QuizSession, error) {
+	return s.repo.GetActiveGroupSession(ctx, chatID)
+}
+
+// MarkQuestionSent records when the current question of a session was shown to the user
+func (s *QuizService) MarkQuestionSent(ctx context.Context, session *models.QuizSession, sentAt time.Time) error {
+	updated := *session
+	updated.QuestionSentAt = &sentAt
+
+	if err := s.repo.SaveProgress(ctx, &updated, session.CurrentQuestionIndex, nil); err != nil {
+		return fmt.Errorf("error marking question as sent: %w", err)
 	}
-	
-	return &session, nil
+
+	*session = updated
+	return nil
 }
 
-// RecordAnswer records a user's answer to a question
-func (s *QuizService) RecordAnswer(userID int64, sessionID int, questionID int, answerGiven string, isCorrect bool) error {
-	_, err := s.db.Exec(`
-		INSERT INTO user_quiz_answers (user_id, session_id, question_id, answer_given, is_correct)
-		VALUES ($1, $2, $3, $4, $5)
-	`, userID, sessionID, questionID, answerGiven, isCorrect)
-	
-	if err != nil {
-		return fmt.Errorf("error recording answer: %w", err)
+// SubmitAnswer records the session user's answer to the question at questionIndex, updates the score
+// and moves the session on to the next question, all in one step.
+// answerIndex is the index of the chosen option in the question's answer options
+// and may be nil when the question was not answered. timeToAnswer may be nil when
+// the time the question was shown is unknown.
+// It returns false without recording anything if the session is no longer at the question,
+// e.g. because the same answer button was pressed twice.
+func (s *QuizService) SubmitAnswer(ctx context.Context, session *models.QuizSession, questionIndex int, answerIndex *int, answerGiven string, isCorrect bool, timeToAnswer *time.Duration) (bool, error) {
+	if questionIndex != session.CurrentQuestionIndex || session.IsComplete() {
+		return false, nil
+	}
+
+	answer := &models.QuizAnswer{
+		UserID:         session.UserID,
+		SessionID:      session.ID,
+		QuestionID:     session.QuestionIDs[questionIndex],
+		AnswerIndex:    answerIndex,
+		AnswerGiven:    answerGiven,
+		IsCorrect:      isCorrect,
+		TimeToAnswerMs: milliseconds(timeToAnswer),
+		UsedHint:       session.HintUsed,
 	}
-	
-	// If the answer is correct, increment the correct_answers count
+
+	// Correct answers given after a hint are counted separately, they score less.
+	// The hint of the answered question doesn't carry over.
+	updated := *session
 	if isCorrect {
-		_, err = s.db.Exec(`
-			UPDATE user_quiz_sessions
-			SET correct_answers = correct_answers + 1
-			WHERE id = $1
-		`, sessionID)
-		
-		if err != nil {
-			return fmt.Errorf("error updating correct answers: %w", err)
+		updated.CorrectAnswers++
+		if session.HintUsed {
+			updated.HintedAnswers++
 		}
 	}
-	
-	return nil
-}
+	updated.CurrentQuestionIndex++
+	updated.HintUsed = false
+	updated.QuestionSentAt = nil
 
-// AdvanceQuizSession moves to the next question in a session
-func (s *QuizService) AdvanceQuizSession(sessionID int) error {
-	_, err := s.db.Exec(`
-		UPDATE user_quiz_sessions
-		SET current_question_index = current_question_index + 1
-		WHERE id = $1
-	`, sessionID)
-	
+	err := s.repo.SaveProgress(ctx, &updated, questionIndex, answer)
+	if errors.Is(err, ErrSessionChanged) {
+		return false, nil
+	}
 	if err != nil {
+		return false, fmt.Errorf("error submitting answer: %w", err)
+	}
+
+	*session = updated
+	return true, nil
+}
+
+// AdvanceQuizSession moves a group quiz session to its next question.
+// Members' answers don't move group sessions on, the question closes for everyone at once.
+func (s *QuizService) AdvanceQuizSession(ctx context.Context, session *models.QuizSession) error {
+	updated := *session
+	updated.CurrentQuestionIndex++
+	updated.HintUsed = false
+	updated.QuestionSentAt = nil
+
+	if err := s.repo.SaveProgress(ctx, &updated, session.CurrentQuestionIndex, nil); err != nil {
 		return fmt.Errorf("error advancing quiz session: %w", err)
 	}
-	
+
+	*session = updated
 	return nil
 }
 
-// CompleteQuizSession marks a quiz session as completed
-func (s *QuizService) CompleteQuizSession(sessionID int) error {
-	_, err := s.db.Exec(`
-		UPDATE user_quiz_sessions
-		SET completed_at = NOW()
-		WHERE id = $1
-	`, sessionID)
-	
-	if err != nil {
+// CompleteQuizSession marks a quiz session as completed.
+// Sessions can be completed before their last question, e.g. when the user abandons them.
+func (s *QuizService) CompleteQuizSession(ctx context.Context, session *models.QuizSession) error {
+	if session.CompletedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	updated := *session
+	updated.CompletedAt = &now
+
+	if err := s.repo.SaveProgress(ctx, &updated, session.CurrentQuestionIndex, nil); err != nil {
 		return fmt.Errorf("error completing quiz session: %w", err)
 	}
-	
+
+	*session = updated
 	return nil
 }
 
 // GetQuizLanguages returns a list of available quiz languages
-func (s *QuizService) GetQuizLanguages() ([]string, error) {
-	rows, err := s.db.Query(`
-		SELECT DISTINCT language
-		FROM quiz_questions
-		ORDER BY language
-	`)
-	
-	if err != nil {
-		return nil, fmt.Errorf("error querying languages: %w", err)
-	}
-	defer rows.Close()
-	
-	var languages []string
-	for rows.Next() {
-		var language string
-		if err := rows.Scan(&language); err != nil {
-			return nil, fmt.Errorf("error scanning language: %w", err)
-		}
-		languages = append(languages, language)
-	}
-	
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating language rows: %w", err)
-	}
-	
-	return languages, nil
+func (s *QuizService) GetQuizLanguages(ctx context.Context) ([]string, error) {
+	return s.repo.GetLanguages(ctx)
 }
 
 // GetUserQuizStats gets statistics about a user's quiz performance
-func (s *QuizService) GetUserQuizStats(userID int64) (map[string]map[string]int, error) {
-	rows, err := s.db.Query(`
-		SELECT 
-			q.language,
-			COUNT(DISTINCT s.id) AS completed_quizzes,
-			COUNT(a.id) AS total_questions,
-			SUM(CASE WHEN a.is_correct THEN 1 ELSE 0 END) AS correct_answers
-		FROM user_quiz_sessions s
-		JOIN user_quiz_answers a ON s.id = a.session_id
-		JOIN quiz_questions q ON a.question_id = q.id
-		WHERE s.user_id = $1 AND s.completed_at IS NOT NULL
-		GROUP BY q.language
-	`, userID)
-	
-	if err != nil {
-		return nil, fmt.Errorf("error querying user stats: %w", err)
-	}
-	defer rows.Close()
-	
-	stats := make(map[string]map[string]int)
-	for rows.Next() {
-		var language string
-		var completedQuizzes, totalQuestions, correctAnswers int
-		
-		err := rows.Scan(&language, &completedQuizzes, &totalQuestions, &correctAnswers)
-		if err != nil {
-			return nil, fmt.Errorf("error scanning stats row: %w", err)
-		}
-		
-		stats[language] = map[string]int{
-			"completed_quizzes": completedQuizzes,
-			"total_questions":   totalQuestions,
-			"correct_answers":   correctAnswers,
-		}
-	}
-	
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating stats rows: %w", err)
+func (s *QuizService) GetUserQuizStats(ctx context.Context, userID int64) (map[string]map[string]int, error) {
+	return s.repo.GetUserQuizStats(ctx, userID)
+}
+
+// SaveQuizPoll remembers which session question a quiz poll was sent for
+func (s *QuizService) SaveQuizPoll(ctx context.Context, poll *models.QuizPoll) error {
+	return s.repo.SaveQuizPoll(ctx, poll)
+}
+
+// GetQuizPoll looks up a quiz poll by its Telegram poll ID.
+// It returns nil if the poll wasn't sent for a quiz session.
+func (s *QuizService) GetQuizPoll(ctx context.Context, pollID string) (*models.QuizPoll, error) {
+	return s.repo.GetQuizPoll(ctx, pollID)
+}
+
+// milliseconds converts a duration that may be unknown to whole milliseconds
+func milliseconds(duration *time.Duration) *int {
+	if duration == nil {
+		return nil
 	}
-	
-	return stats, nil
-}
\ No newline at end of file
+	ms := int(duration.Milliseconds())
+	return &ms
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// newTestQuiz starts a session of three single-choice questions for user 1
+func newTestQuiz(t *testing.T) (*QuizService, *models.QuizSession) {
+	t.Helper()
+	ctx := context.Background()
+
+	repo := NewMemoryQuizRepository()
+	for i := 1; i <= 3; i++ {
+		question := &models.QuizQuestion{
+			Language:           "go",
+			Category:           "Testing",
+			Type:               models.QuestionTypeSingle,
+			QuestionText:       fmt.Sprintf("Question %d?", i),
+			AnswerOptions:      []string{"Yes", "No"},
+			CorrectAnswer:      "Yes",
+			CorrectOptionIndex: 0,
+		}
+		if _, err := repo.UpsertQuestion(ctx, question); err != nil {
+			t.Fatalf("creating question: %v", err)
+		}
+	}
+
+	s := NewQuizService(repo)
+	questions, err := s.GetQuestionsByLanguage(ctx, "go", 10)
+	if err != nil {
+		t.Fatalf("getting questions: %v", err)
+	}
+	if len(questions) != 3 {
+		t.Fatalf("got %d questions, want 3", len(questions))
+	}
+
+	session, err := s.CreateQuizSession(ctx, 1, "go", questions, models.QuizOptions{})
+	if err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+	return s, session
+}
+
+// answer submits an answer to the current question of a session
+func answer(t *testing.T, s *QuizService, session *models.QuizSession, isCorrect bool) {
+	t.Helper()
+
+	submitted, err := s.SubmitAnswer(context.Background(), session, session.CurrentQuestionIndex, nil, "Yes", isCorrect, nil)
+	if err != nil || !submitted {
+		t.Fatalf("SubmitAnswer = %v, %v, want true", submitted, err)
+	}
+}
+
+func TestQuizScoring(t *testing.T) {
+	ctx := context.Background()
+	s, session := newTestQuiz(t)
+
+	answer(t, s, session, true)
+
+	if used, err := s.MarkHintUsed(ctx, session); err != nil || !used {
+		t.Fatalf("MarkHintUsed = %v, %v, want true", used, err)
+	}
+	if used, err := s.MarkHintUsed(ctx, session); err != nil || used {
+		t.Fatalf("second MarkHintUsed = %v, %v, want false", used, err)
+	}
+	answer(t, s, session, true)
+
+	if session.HintUsed {
+		t.Error("hint carried over to the next question")
+	}
+	answer(t, s, session, false)
+
+	if !session.IsComplete() {
+		t.Errorf("session at question %d of %d is not complete", session.CurrentQuestionIndex, len(session.QuestionIDs))
+	}
+	if err := s.CompleteQuizSession(ctx, session); err != nil {
+		t.Fatalf("completing session: %v", err)
+	}
+
+	stored, err := s.GetActiveQuizSession(ctx, 1)
+	if err != nil {
+		t.Fatalf("getting active session: %v", err)
+	}
+	if stored != nil {
+		t.Errorf("completed session %d is still active", stored.ID)
+	}
+
+	if session.CorrectAnswers != 2 || session.HintedAnswers != 1 {
+		t.Errorf("got %d correct answers, %d with a hint, want 2 and 1", session.CorrectAnswers, session.HintedAnswers)
+	}
+
+	stats, err := s.GetUserQuizStats(ctx, 1)
+	if err != nil {
+		t.Fatalf("getting stats: %v", err)
+	}
+	if got := stats["go"]; got["total_questions"] != 3 || got["correct_answers"] != 2 || got["completed_quizzes"] != 1 {
+		t.Errorf("got stats %v, want 3 questions, 2 correct, 1 quiz", got)
+	}
+}
+
+func TestSkipQuestion(t *testing.T) {
+	ctx := context.Background()
+	s, session := newTestQuiz(t)
+	first := session.QuestionIDs[0]
+
+	skipped, err := s.SkipQuestion(ctx, session, first, nil)
+	if err != nil || !skipped {
+		t.Fatalf("SkipQuestion = %v, %v, want true", skipped, err)
+	}
+
+	if last := session.QuestionIDs[len(session.QuestionIDs)-1]; last != first {
+		t.Errorf("skipped question %d is not last, %d is", first, last)
+	}
+	if session.CurrentQuestionIndex != 0 || session.Skips != 1 {
+		t.Errorf("got question index %d and %d skips, want 0 and 1", session.CurrentQuestionIndex, session.Skips)
+	}
+
+	// The skipped question can still be answered when it comes up again
+	answer(t, s, session, true)
+	answer(t, s, session, true)
+	answer(t, s, session, true)
+	if session.CorrectAnswers != 3 {
+		t.Errorf("got %d correct answers, want 3", session.CorrectAnswers)
+	}
+}
+
+func TestAnswerClearsQuestionSentAt(t *testing.T) {
+	ctx := context.Background()
+	s, session := newTestQuiz(t)
+
+	if err := s.MarkQuestionSent(ctx, session, time.Now()); err != nil {
+		t.Fatalf("marking question sent: %v", err)
+	}
+	if session.QuestionSentAt == nil {
+		t.Fatal("QuestionSentAt is nil after MarkQuestionSent")
+	}
+
+	answer(t, s, session, true)
+	if session.QuestionSentAt != nil {
+		t.Error("QuestionSentAt is still set after the answer")
+	}
+
+	if err := s.MarkQuestionSent(ctx, session, time.Now()); err != nil {
+		t.Fatalf("marking question sent: %v", err)
+	}
+	if _, err := s.SkipQuestion(ctx, session, session.QuestionIDs[session.CurrentQuestionIndex], nil); err != nil {
+		t.Fatalf("skipping question: %v", err)
+	}
+	if session.QuestionSentAt != nil {
+		t.Error("QuestionSentAt is still set after the skip")
+	}
+}
+
+func TestStaleSessionIsRejected(t *testing.T) {
+	ctx := context.Background()
+	s, session := newTestQuiz(t)
+
+	// An older copy of the session, e.g. loaded by a second press of an answer button
+	stale := *session
+	answer(t, s, session, true)
+
+	submitted, err := s.SubmitAnswer(ctx, &stale, 0, nil, "Yes", true, nil)
+	if err != nil || submitted {
+		t.Errorf("answering a stale session = %v, %v, want false", submitted, err)
+	}
+	if stale.CorrectAnswers != 0 || stale.CurrentQuestionIndex != 0 {
+		t.Errorf("stale session was updated to question %d with %d correct answers",
+			stale.CurrentQuestionIndex, stale.CorrectAnswers)
+	}
+
+	if skipped, err := s.SkipQuestion(ctx, &stale, stale.QuestionIDs[0], nil); err != nil || skipped {
+		t.Errorf("skipping in a stale session = %v, %v, want false", skipped, err)
+	}
+
+	// The session itself has moved on from the question as well
+	if submitted, err := s.SubmitAnswer(ctx, session, 0, nil, "Yes", true, nil); err != nil || submitted {
+		t.Errorf("answering an earlier question = %v, %v, want false", submitted, err)
+	}
+
+	stored, err := s.GetActiveQuizSession(ctx, 1)
+	if err != nil {
+		t.Fatalf("getting active session: %v", err)
+	}
+	if stored.CurrentQuestionIndex != 1 || stored.CorrectAnswers != 1 {
+		t.Errorf("stored session at question %d with %d correct answers, want 1 and 1",
+			stored.CurrentQuestionIndex, stored.CorrectAnswers)
+	}
+
+	answers, err := s.repo.GetAnswers(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("getting answers: %v", err)
+	}
+	if len(answers) != 1 {
+		t.Errorf("got %d answers, want 1", len(answers))
+	}
+}
+
+func TestRepeatedSkipIsRejected(t *testing.T) {
+	ctx := context.Background()
+	s, session := newTestQuiz(t)
+	first := session.QuestionIDs[0]
+
+	// An older copy of the session, e.g. loaded by a second press of the Skip button
+	stale := *session
+
+	if skipped, err := s.SkipQuestion(ctx, session, first, nil); err != nil || !skipped {
+		t.Fatalf("SkipQuestion = %v, %v, want true", skipped, err)
+	}
+
+	// The session is still at the same index, but with another question
+	if skipped, err := s.SkipQuestion(ctx, session, first, nil); err != nil || skipped {
+		t.Errorf("skipping the skipped question again = %v, %v, want false", skipped, err)
+	}
+	if skipped, err := s.SkipQuestion(ctx, &stale, first, nil); err != nil || skipped {
+		t.Errorf("skipping in a stale session = %v, %v, want false", skipped, err)
+	}
+	if submitted, err := s.SubmitAnswer(ctx, &stale, 0, nil, "Yes", true, nil); err != nil || submitted {
+		t.Errorf("answering the skipped question in a stale session = %v, %v, want false", submitted, err)
+	}
+
+	stored, err := s.GetActiveQuizSession(ctx, 1)
+	if err != nil {
+		t.Fatalf("getting active session: %v", err)
+	}
+	if stored.Skips != 1 || stored.CorrectAnswers != 0 || stored.QuestionIDs[len(stored.QuestionIDs)-1] != first {
+		t.Errorf("stored session has %d skips, %d correct answers and questions %v, want 1 skip, none correct and %d last",
+			stored.Skips, stored.CorrectAnswers, stored.QuestionIDs, first)
+	}
+}
+
+func TestConcurrentAnswersAreCountedOnce(t *testing.T) {
+	ctx := context.Background()
+	s, session := newTestQuiz(t)
+
+	results := make(chan bool)
+	for i := 0; i < 10; i++ {
+		copied := *session
+		go func() {
+			submitted, err := s.SubmitAnswer(ctx, &copied, 0, nil, "Yes", true, nil)
+			if err != nil {
+				t.Errorf("submitting answer: %v", err)
+			}
+			results <- submitted
+		}()
+	}
+
+	count := 0
+	for i := 0; i < 10; i++ {
+		if <-results {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("%d answers were submitted, want 1", count)
+	}
+
+	stored, err := s.GetActiveQuizSession(ctx, 1)
+	if err != nil {
+		t.Fatalf("getting active session: %v", err)
+	}
+	if stored.CurrentQuestionIndex != 1 || stored.CorrectAnswers != 1 {
+		t.Errorf("stored session at question %d with %d correct answers, want 1 and 1",
+			stored.CurrentQuestionIndex, stored.CorrectAnswers)
+	}
+}
+
+func TestGroupLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	s, session := newTestQuiz(t)
+	fast, slow := 2*time.Second, 5*time.Second
+
+	for _, a := range []struct {
+		userID    int64
+		question  int
+		isCorrect bool
+		time      time.Duration
+	}{
+		{userID: 10, question: 0, isCorrect: true, time: slow},
+		{userID: 20, question: 0, isCorrect: true, time: fast},
+		{userID: 30, question: 0, isCorrect: false, time: fast},
+		{userID: 10, question: 1, isCorrect: true, time: slow},
+		{userID: 20, question: 1, isCorrect: true, time: fast},
+	} {
+		recorded, err := s.RecordGroupAnswer(ctx, a.userID, session.ID, session.QuestionIDs[a.question], nil, "", a.isCorrect, &a.time)
+		if err != nil || !recorded {
+			t.Fatalf("RecordGroupAnswer = %v, %v, want true", recorded, err)
+		}
+	}
+
+	// Members answer each question once
+	if recorded, err := s.RecordGroupAnswer(ctx, 30, session.ID, session.QuestionIDs[0], nil, "", true, &fast); err != nil || recorded {
+		t.Errorf("second answer = %v, %v, want false", recorded, err)
+	}
+
+	scores, err := s.GetGroupLeaderboard(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("getting leaderboard: %v", err)
+	}
+
+	want := []models.GroupScore{
+		{UserID: 20, Correct: 2, Answered: 2, TotalTimeMs: 4000},
+		{UserID: 10, Correct: 2, Answered: 2, TotalTimeMs: 10000},
+		{UserID: 30, Correct: 0, Answered: 1, TotalTimeMs: 2000},
+	}
+	if fmt.Sprint(scores) != fmt.Sprint(want) {
+		t.Errorf("got leaderboard %v, want %v", scores, want)
+	}
+}
+
+func TestLeaderboardHintPenalty(t *testing.T) {
+	ctx := context.Background()
+	s, hinted := newTestQuiz(t)
+
+	// User 1 answers every question correctly, one of them after a hint
+	answer(t, s, hinted, true)
+	if used, err := s.MarkHintUsed(ctx, hinted); err != nil || !used {
+		t.Fatalf("MarkHintUsed = %v, %v, want true", used, err)
+	}
+	answer(t, s, hinted, true)
+	answer(t, s, hinted, true)
+	if err := s.CompleteQuizSession(ctx, hinted); err != nil {
+		t.Fatalf("completing session: %v", err)
+	}
+
+	// User 2 answers every question correctly without hints
+	questions, err := s.GetQuestionsByLanguage(ctx, "go", 10)
+	if err != nil {
+		t.Fatalf("getting questions: %v", err)
+	}
+	unhinted, err := s.CreateQuizSession(ctx, 2, "go", questions, models.QuizOptions{})
+	if err != nil {
+		t.Fatalf("creating session: %v", err)
+	}
+	for range questions {
+		answer(t, s, unhinted, true)
+	}
+	if err := s.CompleteQuizSession(ctx, unhinted); err != nil {
+		t.Fatalf("completing session: %v", err)
+	}
+
+	entries, err := s.GetLeaderboard(ctx, "go", nil, 1, 10)
+	if err != nil {
+		t.Fatalf("getting leaderboard: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d leaderboard entries, want 2", len(entries))
+	}
+
+	if entries[0].User.ID != 2 || entries[0].Accuracy() != 100 {
+		t.Errorf("first place is user %d with %.1f%%, want user 2 with 100%%", entries[0].User.ID, entries[0].Accuracy())
+	}
+	// 2.5 points out of 3
+	if got, want := entries[1].Accuracy(), 2.5/3*100; entries[1].User.ID != 1 || math.Abs(got-want) > 1e-9 {
+		t.Errorf("second place is user %d with %.1f%%, want user 1 with %.1f%%", entries[1].User.ID, got, want)
+	}
+}
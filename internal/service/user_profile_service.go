@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/amiosamu/interview-match-bot/internal/models"
+)
+
+// UserProfileService stores the Telegram names and settings of users,
+// so they are known after a restart and to users who never met them
+type UserProfileService struct {
+	db *sql.DB
+}
+
+// NewUserProfileService creates a new UserProfileService
+func NewUserProfileService(db *sql.DB) *UserProfileService {
+	return &UserProfileService{db: db}
+}
+
+// SaveProfile stores a user's current Telegram names, keeping their settings.
+// The user just talked to the bot, so they no longer count as having blocked it.
+func (s *UserProfileService) SaveProfile(ctx context.Context, user *models.User) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, username, first_name, last_name)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET username = EXCLUDED.username, first_name = EXCLUDED.first_name,
+			last_name = EXCLUDED.last_name, blocked_at = NULL, updated_at = NOW()
+	`, user.ID, user.Username, user.FirstName, user.LastName)
+
+	if err != nil {
+		return fmt.Errorf("error saving user profile: %w", err)
+	}
+
+	return nil
+}
+
+// MarkBlocked records that messages to a user fail because they blocked the bot
+func (s *UserProfileService) MarkBlocked(ctx context.Context, userID int64) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, blocked_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET blocked_at = EXCLUDED.blocked_at, updated_at = NOW()
+	`, userID)
+
+	if err != nil {
+		return fmt.Errorf("error marking user as blocked: %w", err)
+	}
+
+	return nil
+}
+
+// SetLeaderboardOptOut hides a user from leaderboards or shows them again
+func (s *UserProfileService) SetLeaderboardOptOut(ctx context.Context, userID int64, optOut bool) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, leaderboard_opt_out)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET leaderboard_opt_out = EXCLUDED.leaderboard_opt_out, updated_at = NOW()
+	`, userID, optOut)
+
+	if err != nil {
+		return fmt.Errorf("error updating leaderboard setting: %w", err)
+	}
+
+	return nil
+}
+
+// IsLeaderboardOptOut reports whether a user chose to be hidden from leaderboards
+func (s *UserProfileService) IsLeaderboardOptOut(ctx context.Context, userID int64) (bool, error) {
+	var optOut bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT leaderboard_opt_out
+		FROM user_profiles
+		WHERE user_id = $1
+	`, userID).Scan(&optOut)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("error querying leaderboard setting: %w", err)
+	}
+
+	return optOut, nil
+}
+
+// SetDailyLanguage subscribes a user to the daily challenge of a language.
+// An empty language unsubscribes them.
+func (s *UserProfileService) SetDailyLanguage(ctx context.Context, userID int64, language string) error {
+	var languageValue sql.NullString
+	if language != "" {
+		languageValue = sql.NullString{String: language, Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO user_profiles (user_id, daily_language)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET daily_language = EXCLUDED.daily_language, updated_at = NOW()
+	`, userID, languageValue)
+
+	if err != nil {
+		return fmt.Errorf("error updating daily challenge setting: %w", err)
+	}
+
+	return nil
+}
+
+// GetDailyLanguage returns the language of the daily challenge a user is subscribed to,
+// or an empty string if they are not subscribed
+func (s *UserProfileService) GetDailyLanguage(ctx context.Context, userID int64) (string, error) {
+	var language sql.NullString
+	err := s.db.QueryRowContext(ctx, `
+		SELECT daily_language
+		FROM user_profiles
+		WHERE user_id = $1
+	`, userID).Scan(&language)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("error querying daily challenge setting: %w", err)
+	}
+
+	return language.String, nil
+}
@@ -34,14 +34,15 @@ func (s *UserStore) GetUser(userID int64) (*models.User, bool) {
 	return user, exists
 }
 
-// FindMatches returns users that match the given criteria
+// FindMatches returns users that match the given criteria.
+// Inactive users are left out, they wouldn't get the match notification.
 func (s *UserStore) FindMatches(userID int64, field, level string) []*models.User {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	var matches []*models.User
 	for id, user := range s.users {
-		if id != userID && user.Field == field && user.Level == level {
+		if id != userID && !user.Inactive && user.Field == field && user.Level == level {
 			matches = append(matches, user)
 		}
 	}
@@ -71,3 +72,17 @@ func (s *UserStore) SetUserLevel(userID int64, level string) {
 	}
 	user.Level = level
 }
+
+// SetUserInactive marks a user as unreachable or reachable again.
+// It returns whether the user was known and their state changed.
+func (s *UserStore) SetUserInactive(userID int64, inactive bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	user, exists := s.users[userID]
+	if !exists || user.Inactive == inactive {
+		return false
+	}
+	user.Inactive = inactive
+	return true
+}
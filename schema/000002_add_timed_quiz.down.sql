@@ -0,0 +1,6 @@
+ALTER TABLE user_quiz_answers
+    DROP COLUMN IF EXISTS time_to_answer_ms;
+
+ALTER TABLE user_quiz_sessions
+    DROP COLUMN IF EXISTS question_sent_at,
+    DROP COLUMN IF EXISTS timed;
@@ -0,0 +1,8 @@
+-- Timed quiz mode
+ALTER TABLE user_quiz_sessions
+    ADD COLUMN IF NOT EXISTS timed BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS question_sent_at TIMESTAMP;
+
+-- Time it took the user to answer, NULL when unknown
+ALTER TABLE user_quiz_answers
+    ADD COLUMN IF NOT EXISTS time_to_answer_ms INT;
@@ -0,0 +1,8 @@
+ALTER TABLE user_quiz_answers
+    DROP COLUMN IF EXISTS answer_index;
+
+ALTER TABLE user_quiz_sessions
+    DROP COLUMN IF EXISTS option_orders;
+
+ALTER TABLE quiz_questions
+    DROP COLUMN IF EXISTS correct_option_index;
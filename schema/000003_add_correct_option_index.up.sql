@@ -0,0 +1,27 @@
+-- Canonical index of the correct option in answer_options
+ALTER TABLE quiz_questions
+    ADD COLUMN IF NOT EXISTS correct_option_index INT;
+
+-- Convert existing correct_answer strings. An exact match wins; otherwise
+-- compare with escaped newlines and backslashes unescaped, since seed data
+-- mixes '20\n10' and '20\\n10'. Questions without a match stay NULL and
+-- are not offered in quizzes until fixed.
+UPDATE quiz_questions q
+SET correct_option_index = (
+    SELECT o.ord - 1
+    FROM jsonb_array_elements_text(q.answer_options) WITH ORDINALITY AS o(opt, ord)
+    WHERE o.opt = q.correct_answer
+       OR replace(replace(o.opt, E'\\\\', E'\\'), E'\\n', E'\n') =
+          replace(replace(q.correct_answer, E'\\\\', E'\\'), E'\\n', E'\n')
+    ORDER BY (o.opt = q.correct_answer) DESC, o.ord
+    LIMIT 1
+)
+WHERE correct_option_index IS NULL;
+
+-- Per-session shuffled option order, one permutation per question
+ALTER TABLE user_quiz_sessions
+    ADD COLUMN IF NOT EXISTS option_orders JSONB;
+
+-- Index of the chosen option in answer_options, NULL when unanswered
+ALTER TABLE user_quiz_answers
+    ADD COLUMN IF NOT EXISTS answer_index INT;
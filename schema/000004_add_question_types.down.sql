@@ -0,0 +1,15 @@
+DELETE FROM user_quiz_answers
+WHERE question_id IN (SELECT id FROM quiz_questions WHERE type <> 'single');
+
+DELETE FROM quiz_questions WHERE type <> 'single';
+
+ALTER TABLE user_quiz_answers
+    ALTER COLUMN answer_given TYPE VARCHAR(255);
+
+ALTER TABLE quiz_questions
+    ALTER COLUMN correct_answer TYPE VARCHAR(255);
+
+ALTER TABLE quiz_questions
+    DROP COLUMN IF EXISTS accepted_answers,
+    DROP COLUMN IF EXISTS correct_option_indexes,
+    DROP COLUMN IF EXISTS type;
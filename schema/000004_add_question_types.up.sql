@@ -0,0 +1,12 @@
+-- Question types: single, multi, text and output
+ALTER TABLE quiz_questions
+    ADD COLUMN IF NOT EXISTS type VARCHAR(20) NOT NULL DEFAULT 'single',
+    ADD COLUMN IF NOT EXISTS correct_option_indexes JSONB,
+    ADD COLUMN IF NOT EXISTS accepted_answers JSONB;
+
+ALTER TABLE quiz_questions
+    ALTER COLUMN correct_answer TYPE TEXT;
+
+-- Free-text and output answers can be longer than a button label
+ALTER TABLE user_quiz_answers
+    ALTER COLUMN answer_given TYPE TEXT;
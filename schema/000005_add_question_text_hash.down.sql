@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_quiz_questions_text_hash;
+
+DROP TRIGGER IF EXISTS quiz_questions_text_hash ON quiz_questions;
+DROP FUNCTION IF EXISTS set_quiz_question_text_hash();
+DROP FUNCTION IF EXISTS quiz_question_text_hash(TEXT, TEXT);
+
+ALTER TABLE quiz_questions
+    DROP COLUMN IF EXISTS text_hash;
@@ -0,0 +1,67 @@
+-- Hash of language and normalized question text, used to de-duplicate imports.
+-- Must match models.QuizQuestion.TextHash.
+ALTER TABLE quiz_questions
+    ADD COLUMN IF NOT EXISTS text_hash CHAR(64);
+
+CREATE OR REPLACE FUNCTION quiz_question_text_hash(language TEXT, question_text TEXT)
+RETURNS CHAR(64) AS $$
+    SELECT encode(sha256(convert_to(
+        language || E'\n' || lower(regexp_replace(regexp_replace(question_text, '^\s+|\s+$', '', 'g'), '\s+', ' ', 'g')),
+        'UTF8')), 'hex')
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- Keep the hash up to date for rows written by seeds and hand-written SQL
+CREATE OR REPLACE FUNCTION set_quiz_question_text_hash()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.text_hash := quiz_question_text_hash(NEW.language, NEW.question_text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER quiz_questions_text_hash
+    BEFORE INSERT OR UPDATE OF language, question_text ON quiz_questions
+    FOR EACH ROW EXECUTE FUNCTION set_quiz_question_text_hash();
+
+UPDATE quiz_questions
+SET text_hash = quiz_question_text_hash(language, question_text)
+WHERE text_hash IS NULL;
+
+-- Merge existing duplicates into the oldest copy before enforcing uniqueness
+CREATE TEMPORARY TABLE duplicate_quiz_questions AS
+SELECT id, keep_id
+FROM (
+    SELECT id, MIN(id) OVER (PARTITION BY text_hash) AS keep_id
+    FROM quiz_questions
+) d
+WHERE id <> keep_id;
+
+UPDATE user_quiz_answers a
+SET question_id = d.keep_id
+FROM duplicate_quiz_questions d
+WHERE a.question_id = d.id;
+
+-- Sessions list their questions in JSON, so the deleted copies are replaced there too.
+-- Duels, which list them the same way, are only added in 000008.
+UPDATE user_quiz_sessions s
+SET question_ids = (
+    SELECT jsonb_agg(COALESCE(d.keep_id, q.id::INT) ORDER BY q.position)
+    FROM jsonb_array_elements_text(s.question_ids) WITH ORDINALITY AS q(id, position)
+    LEFT JOIN duplicate_quiz_questions d ON d.id = q.id::INT
+)
+WHERE EXISTS (
+    SELECT 1
+    FROM jsonb_array_elements_text(s.question_ids) AS q(id)
+    JOIN duplicate_quiz_questions d ON d.id = q.id::INT
+);
+
+DELETE FROM quiz_questions q
+USING duplicate_quiz_questions d
+WHERE q.id = d.id;
+
+DROP TABLE duplicate_quiz_questions;
+
+ALTER TABLE quiz_questions
+    ALTER COLUMN text_hash SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quiz_questions_text_hash ON quiz_questions(text_hash);
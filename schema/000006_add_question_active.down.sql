@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_quiz_questions_active_language;
+
+ALTER TABLE quiz_questions
+    DROP COLUMN IF EXISTS active;
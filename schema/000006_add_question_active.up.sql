@@ -0,0 +1,5 @@
+-- Questions failing validation can be disabled without deleting them
+ALTER TABLE quiz_questions
+    ADD COLUMN IF NOT EXISTS active BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX IF NOT EXISTS idx_quiz_questions_active_language ON quiz_questions(language) WHERE active;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_quiz_polls_session;
+DROP TABLE IF EXISTS quiz_polls;
+
+ALTER TABLE user_quiz_sessions
+    DROP COLUMN IF EXISTS render_mode;
@@ -0,0 +1,15 @@
+-- How questions of a session are shown: inline keyboards or native quiz polls
+ALTER TABLE user_quiz_sessions
+    ADD COLUMN IF NOT EXISTS render_mode VARCHAR(20) NOT NULL DEFAULT 'keyboard';
+
+-- Quiz polls sent for session questions, to map poll answers back to sessions
+CREATE TABLE IF NOT EXISTS quiz_polls (
+    poll_id VARCHAR(64) PRIMARY KEY,
+    session_id INT NOT NULL REFERENCES user_quiz_sessions(id),
+    question_index INT NOT NULL,
+    chat_id BIGINT NOT NULL,
+    message_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_quiz_polls_session ON quiz_polls(session_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_user_quiz_sessions_duel;
+
+ALTER TABLE user_quiz_sessions
+    DROP COLUMN IF EXISTS duel_id;
+
+DROP TABLE IF EXISTS quiz_duels;
@@ -0,0 +1,19 @@
+-- Quiz duels: two users answering the same question set
+CREATE TABLE IF NOT EXISTS quiz_duels (
+    id SERIAL PRIMARY KEY,
+    challenger_id BIGINT NOT NULL,
+    opponent_id BIGINT,
+    language VARCHAR(50) NOT NULL,
+    question_ids JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    announced_questions INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+-- Each duel participant answers in a quiz session of their own
+ALTER TABLE user_quiz_sessions
+    ADD COLUMN IF NOT EXISTS duel_id INT REFERENCES quiz_duels(id);
+
+CREATE INDEX IF NOT EXISTS idx_user_quiz_sessions_duel ON user_quiz_sessions(duel_id) WHERE duel_id IS NOT NULL;
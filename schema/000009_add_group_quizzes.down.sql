@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_user_quiz_answers_session_question;
+DROP INDEX IF EXISTS idx_user_quiz_sessions_group_active;
+
+ALTER TABLE user_quiz_sessions
+    DROP COLUMN IF EXISTS chat_id;
@@ -0,0 +1,8 @@
+-- Group quizzes: one session per group chat, answered by any member
+ALTER TABLE user_quiz_sessions
+    ADD COLUMN IF NOT EXISTS chat_id BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_user_quiz_sessions_group_active ON user_quiz_sessions(chat_id) WHERE chat_id IS NOT NULL AND completed_at IS NULL;
+
+-- Members' answers to a group question are looked up together
+CREATE INDEX IF NOT EXISTS idx_user_quiz_answers_session_question ON user_quiz_answers(session_id, question_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_quiz_sessions_completed;
+
+DROP TABLE IF EXISTS user_profiles;
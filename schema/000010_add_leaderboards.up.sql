@@ -0,0 +1,12 @@
+-- Telegram names and settings of quiz players, kept across restarts
+CREATE TABLE IF NOT EXISTS user_profiles (
+    user_id BIGINT PRIMARY KEY,
+    username VARCHAR(255) NOT NULL DEFAULT '',
+    first_name VARCHAR(255) NOT NULL DEFAULT '',
+    last_name VARCHAR(255) NOT NULL DEFAULT '',
+    leaderboard_opt_out BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Leaderboards rank completed sessions by language and completion time
+CREATE INDEX IF NOT EXISTS idx_user_quiz_sessions_completed ON user_quiz_sessions(language, completed_at) WHERE completed_at IS NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE user_profiles
+    DROP COLUMN IF EXISTS daily_sent_on,
+    DROP COLUMN IF EXISTS daily_language;
+
+DROP TABLE IF EXISTS daily_challenge_answers;
@@ -0,0 +1,17 @@
+-- Answers to the daily challenge, one per user, day and language
+CREATE TABLE IF NOT EXISTS daily_challenge_answers (
+    user_id BIGINT NOT NULL,
+    challenge_date DATE NOT NULL,
+    language VARCHAR(50) NOT NULL,
+    question_id INT NOT NULL REFERENCES quiz_questions(id),
+    answer_index INT NOT NULL,
+    is_correct BOOLEAN NOT NULL,
+    answered_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, challenge_date, language)
+);
+
+-- Users opted in to the daily broadcast choose its language;
+-- daily_sent_on keeps the broadcast from reaching a user twice a day
+ALTER TABLE user_profiles
+    ADD COLUMN IF NOT EXISTS daily_language VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS daily_sent_on DATE;
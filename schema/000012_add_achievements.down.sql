@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_quiz_answers_user;
+
+DROP TABLE IF EXISTS user_achievements;
@@ -0,0 +1,9 @@
+-- Achievements unlocked by users
+CREATE TABLE IF NOT EXISTS user_achievements (
+    user_id BIGINT NOT NULL,
+    achievement_id VARCHAR(50) NOT NULL,
+    unlocked_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, achievement_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_quiz_answers_user ON user_quiz_answers(user_id);
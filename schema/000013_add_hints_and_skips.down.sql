@@ -0,0 +1,11 @@
+ALTER TABLE user_quiz_answers
+    DROP COLUMN IF EXISTS skipped,
+    DROP COLUMN IF EXISTS used_hint;
+
+ALTER TABLE user_quiz_sessions
+    DROP COLUMN IF EXISTS skips,
+    DROP COLUMN IF EXISTS hinted_answers,
+    DROP COLUMN IF EXISTS hint_used;
+
+ALTER TABLE quiz_questions
+    DROP COLUMN IF EXISTS hint;
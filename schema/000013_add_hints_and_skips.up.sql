@@ -0,0 +1,15 @@
+-- Optional hint shown by the Hint button; without one, two wrong options are removed
+ALTER TABLE quiz_questions
+    ADD COLUMN IF NOT EXISTS hint TEXT NOT NULL DEFAULT '';
+
+-- hint_used applies to the current question and is reset when the session moves on;
+-- hinted_answers counts correct answers given after a hint, which score half
+ALTER TABLE user_quiz_sessions
+    ADD COLUMN IF NOT EXISTS hint_used BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS hinted_answers INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS skips INT NOT NULL DEFAULT 0;
+
+-- Skipped questions are recorded as answers that don't count
+ALTER TABLE user_quiz_answers
+    ADD COLUMN IF NOT EXISTS used_hint BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS skipped BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_question_bookmarks_user_created;
+
+DROP TABLE IF EXISTS question_bookmarks;
@@ -0,0 +1,9 @@
+-- Questions users saved to revisit
+CREATE TABLE IF NOT EXISTS question_bookmarks (
+    user_id BIGINT NOT NULL,
+    question_id INT NOT NULL REFERENCES quiz_questions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_bookmarks_user_created ON question_bookmarks(user_id, created_at DESC);
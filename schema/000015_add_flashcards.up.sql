@@ -0,0 +1,11 @@
+-- How well users know questions, rated by themselves in flashcard mode.
+-- familiarity grows by one with every "knew it" and drops to 0 with every "didn't".
+CREATE TABLE IF NOT EXISTS question_familiarity (
+    user_id BIGINT NOT NULL,
+    question_id INT NOT NULL REFERENCES quiz_questions(id) ON DELETE CASCADE,
+    familiarity INT NOT NULL DEFAULT 0,
+    knew_count INT NOT NULL DEFAULT 0,
+    missed_count INT NOT NULL DEFAULT 0,
+    reviewed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, question_id)
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_user_quiz_answers_unique;
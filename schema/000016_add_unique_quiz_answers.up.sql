@@ -0,0 +1,27 @@
+-- Every user answers a question of a session once; skipped questions are recorded
+-- as answers that don't count and come back later, so they may repeat.
+-- Duplicates recorded by repeated button presses are removed first, keeping the first answer.
+DELETE FROM user_quiz_answers a
+USING user_quiz_answers earlier
+WHERE a.session_id = earlier.session_id AND a.question_id = earlier.question_id
+    AND a.user_id = earlier.user_id AND NOT a.skipped AND NOT earlier.skipped
+    AND a.id > earlier.id;
+
+-- The repeated presses were also counted in the sessions, so their scores are recounted
+-- from the answers that remain. Group sessions don't keep a score of their own.
+UPDATE user_quiz_sessions s
+SET correct_answers = counted.correct, hinted_answers = counted.hinted
+FROM (
+    SELECT qs.id,
+        COUNT(a.id) FILTER (WHERE a.is_correct AND NOT a.skipped) AS correct,
+        COUNT(a.id) FILTER (WHERE a.is_correct AND NOT a.skipped AND a.used_hint) AS hinted
+    FROM user_quiz_sessions qs
+    LEFT JOIN user_quiz_answers a ON a.session_id = qs.id AND a.user_id = qs.user_id
+    WHERE qs.chat_id IS NULL
+    GROUP BY qs.id
+) counted
+WHERE s.id = counted.id
+    AND (s.correct_answers > counted.correct OR s.hinted_answers > counted.hinted);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_quiz_answers_unique
+    ON user_quiz_answers(session_id, question_id, user_id) WHERE NOT skipped;
@@ -0,0 +1,2 @@
+ALTER TABLE user_profiles
+    DROP COLUMN IF EXISTS blocked_at;
@@ -0,0 +1,4 @@
+-- Set when a message to the user failed because they blocked the bot or deleted their account.
+-- Blocked users get no broadcasts until they message the bot again.
+ALTER TABLE user_profiles
+    ADD COLUMN IF NOT EXISTS blocked_at TIMESTAMP;
@@ -0,0 +1,4 @@
+UPDATE quiz_questions
+SET answer_options = '["var x int = 10", "let x = 10", "x := 10", "Both A and C"]',
+    correct_answer = 'Both A and C'
+WHERE answer_options = '["var x int = 10", "let x = 10", "x := 10", "Both var x int = 10 and x := 10"]';
@@ -0,0 +1,6 @@
+-- Options are shuffled, so an option can't refer to others by their letter.
+-- Name the declarations instead of the wording seeded by 000001.
+UPDATE quiz_questions
+SET answer_options = '["var x int = 10", "let x = 10", "x := 10", "Both var x int = 10 and x := 10"]',
+    correct_answer = 'Both var x int = 10 and x := 10'
+WHERE answer_options = '["var x int = 10", "let x = 10", "x := 10", "Both A and C"]';